@@ -0,0 +1,64 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/tetratelabs/telemetry"
+)
+
+// ctxLoggerKey is the Context key of the ambient Logger.
+type ctxLoggerKey struct{}
+
+// fallback optionally holds the Logger returned by FromContext if no Logger
+// is found.
+var fallback atomic.Value
+
+// loggerHolder allows storing differing telemetry.Logger implementations in
+// an atomic.Value.
+type loggerHolder struct {
+	telemetry.Logger
+}
+
+// NewContext returns a Context holding the provided Logger as ambient Logger,
+// retrievable with FromContext. This allows deep call stacks to obtain the
+// request Logger without threading it through every signature.
+func NewContext(ctx context.Context, l telemetry.Logger) context.Context {
+	return context.WithValue(ctx, ctxLoggerKey{}, l)
+}
+
+// FromContext returns the ambient Logger found in the provided Context, with
+// that Context attached. If no Logger is found, the fallback Logger is
+// returned instead.
+func FromContext(ctx context.Context) telemetry.Logger {
+	if l, ok := ctx.Value(ctxLoggerKey{}).(telemetry.Logger); ok {
+		return l.Context(ctx)
+	}
+	if h, ok := fallback.Load().(loggerHolder); ok {
+		return h.Context(ctx)
+	}
+	return Default().Context(ctx)
+}
+
+// SetFallback sets the Logger returned by FromContext if no ambient Logger is
+// found. By default, the package's default Logger is used.
+func SetFallback(l telemetry.Logger) {
+	if l == nil {
+		return
+	}
+	fallback.Store(loggerHolder{l})
+}
@@ -0,0 +1,160 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithAsync enables async mode. Records are handed to a background goroutine
+// through a queue of the provided size, taking writes off the logging path.
+// If the queue is full, logging blocks until room is available. Async
+// Loggers register themselves as Flusher, so FlushAll and Sync drain their
+// queues. Use Close to stop the background goroutine once the Logger is no
+// longer used.
+func WithAsync(queueSize int) Option {
+	return func(o *options) {
+		o.asyncQueueSize = queueSize
+	}
+}
+
+// WithQueueLatency adds a queue_latency_ms field to records in async mode,
+// holding the time records spent in the queue. Together with the record time,
+// captured at enqueue time, it allows consumers to distinguish when something
+// happened from when it was written during backlog processing.
+func WithQueueLatency() Option {
+	return func(o *options) {
+		o.queueLatency = true
+	}
+}
+
+// asyncItem holds a queued batch of entries or a flush request.
+type asyncItem struct {
+	entries  []*Entry
+	enqueued time.Time
+	flushed  chan struct{}
+}
+
+// asyncSink emits entries to sink from a background goroutine.
+type asyncSink struct {
+	sink    sink
+	clock   Clock
+	latency bool
+	queue   chan asyncItem
+	// done is closed once run returns.
+	done       chan struct{}
+	deregister func()
+
+	// mtx guards closed, preventing sends on the closed queue.
+	mtx    sync.RWMutex
+	closed bool
+}
+
+func newAsyncSink(s sink, o *options) *asyncSink {
+	a := &asyncSink{
+		sink:    s,
+		clock:   o.clock,
+		latency: o.queueLatency,
+		queue:   make(chan asyncItem, o.asyncQueueSize),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+	a.deregister = RegisterFlusher(a)
+
+	return a
+}
+
+func (a *asyncSink) emit(entries ...*Entry) error {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+
+	if a.closed {
+		return ErrClosed
+	}
+	a.queue <- asyncItem{entries: entries, enqueued: a.clock.Now()}
+	return nil
+}
+
+// Flush implements Flusher.
+func (a *asyncSink) Flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+	a.mtx.RLock()
+	if a.closed {
+		a.mtx.RUnlock()
+		return nil
+	}
+	select {
+	case a.queue <- asyncItem{flushed: flushed}:
+		a.mtx.RUnlock()
+	case <-ctx.Done():
+		a.mtx.RUnlock()
+		return ctx.Err()
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close stops the background goroutine after draining the queue and closes
+// the wrapped sink.
+func (a *asyncSink) close() error {
+	a.mtx.Lock()
+	if a.closed {
+		a.mtx.Unlock()
+		return nil
+	}
+	a.closed = true
+	close(a.queue)
+	a.mtx.Unlock()
+
+	a.deregister()
+	<-a.done
+	return closeSink(a.sink)
+}
+
+func (a *asyncSink) run() {
+	defer close(a.done)
+	for item := range a.queue {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		entries := item.entries
+		if a.latency {
+			entries = withQueueLatency(entries, a.clock.Now().Sub(item.enqueued).Milliseconds())
+		}
+		_ = a.sink.emit(entries...)
+	}
+}
+
+// withQueueLatency returns copies of entries holding the queue_latency_ms
+// field. The queued entries are left untouched, as the logging goroutine might
+// still read them, e.g. to mirror them as span events.
+func withQueueLatency(entries []*Entry, latency int64) []*Entry {
+	res := make([]*Entry, 0, len(entries))
+	for _, e := range entries {
+		c := *e
+		c.KeyValues = make([]interface{}, 0, len(e.KeyValues)+2)
+		c.KeyValues = append(c.KeyValues, e.KeyValues...)
+		c.KeyValues = append(c.KeyValues, "queue_latency_ms", latency)
+		res = append(res, &c)
+	}
+	return res
+}
@@ -0,0 +1,87 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tetratelabs/telemetry-gokit-log/logtest"
+)
+
+func TestAsyncFlush(t *testing.T) {
+	w := logtest.NewWriter()
+	l := NewSyncLogfmt(w, WithAsync(16))
+	defer func() { _ = l.Close() }()
+
+	w.SetFaults(logtest.Faults{Latency: time.Millisecond})
+	for i := 0; i < 10; i++ {
+		l.Info("queued", "i", i)
+	}
+	if err := FlushAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if lines := w.Lines(); len(lines) != 10 {
+		t.Errorf("expected 10 records after flush, got %d", len(lines))
+	}
+}
+
+func TestAsyncFlushCanceled(t *testing.T) {
+	w := logtest.NewWriter()
+	l := NewSyncLogfmt(w, WithAsync(1))
+	defer func() { _ = l.Close() }()
+
+	w.SetFaults(logtest.Faults{Latency: 50 * time.Millisecond})
+	l.Info("slow")
+	l.Info("slow")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := l.sink.(*asyncSink).Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestAsyncClose(t *testing.T) {
+	w := logtest.NewWriter()
+	l := NewSyncLogfmt(w, WithAsync(16))
+
+	w.SetFaults(logtest.Faults{Latency: time.Millisecond})
+	for i := 0; i < 10; i++ {
+		l.Info("queued", "i", i)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := w.Lines(); len(lines) != 10 {
+		t.Errorf("expected 10 records drained by close, got %d", len(lines))
+	}
+
+	if err := l.Close(); err != nil {
+		t.Errorf("expected repeated close to succeed, got %v", err)
+	}
+	if err := l.sink.emit(l.entry(Info, "closed", nil, nil)); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+	if err := l.sink.(*asyncSink).Flush(context.Background()); err != nil {
+		t.Errorf("expected flush after close to succeed, got %v", err)
+	}
+	l.Info("dropped")
+	if lines := w.Lines(); len(lines) != 10 {
+		t.Errorf("expected records after close to be dropped, got %d", len(lines))
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AzureMonitorScope holds the OAuth scope of tokens for the Azure Monitor Logs
+// ingestion API.
+const AzureMonitorScope = "https://monitor.azure.com/.default"
+
+// azureAPIVersion holds the version of the Logs ingestion API used.
+const azureAPIVersion = "2023-01-01"
+
+// TokenFunc returns a bearer token for the provided OAuth scope. When using
+// the Azure SDK for Go, it typically wraps the GetToken method of an
+// azidentity credential.
+type TokenFunc func(ctx context.Context, scope string) (string, error)
+
+// AzureMonitorConfig configures a Logger writing to the Azure Monitor Logs
+// ingestion API through a data collection rule (DCR).
+type AzureMonitorConfig struct {
+	// Endpoint holds the logs ingestion endpoint of the data collection
+	// endpoint or rule, e.g. https://my-dce.westeurope-1.ingest.monitor.azure.com.
+	Endpoint string
+	// RuleID holds the immutable id of the data collection rule.
+	RuleID string
+	// Stream holds the name of the stream declared in the data collection
+	// rule, e.g. Custom-AppLogs_CL.
+	Stream string
+	// Token provides the Microsoft Entra ID (AAD) bearer tokens.
+	Token TokenFunc
+	// Client holds the http.Client used, e.g. with a transport configured for
+	// the proxy of the network. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Columns maps record keys to stream columns. The time, message, level
+	// and error of records are emitted as the TimeGenerated, Message, Level
+	// and Error columns, unless mapped otherwise using the keys set through
+	// WithKeyNames.
+	Columns map[string]string
+	// PropertiesColumn holds the dynamic column receiving the key-value pairs
+	// without a column mapping. If empty, these pairs are dropped.
+	PropertiesColumn string
+	// Compression compresses the request bodies if set, e.g. using
+	// GzipCompressor.
+	Compression Compressor
+	// Batch configures the batching of records. If Batch.MaxBytes is not
+	// set, DefaultAzureMonitorMaxBytes is used.
+	Batch BatchConfig
+}
+
+// DefaultAzureMonitorMaxBytes holds the default maximum size of a request
+// to the Logs ingestion API, which rejects requests larger than 1 MB.
+const DefaultAzureMonitorMaxBytes = 1 << 20
+
+// NewAzureMonitor returns a new telemetry.Logger implementation writing
+// records to the Azure Monitor Logs ingestion API, removing the need for a
+// separate agent. Records are mapped to the columns of the configured stream
+// and sent in batches as configured by cfg.Batch, which are also sent
+// periodically, when flushed through FlushAll or Sync, and when the Logger is
+// closed. Requests throttled with status 429 or 503 are retried with backoff.
+// Failures of periodic flushes are reported to the ErrorHandler. As records
+// are sent synchronously once a batch is full, combining it with WithAsync is
+// recommended.
+func NewAzureMonitor(cfg AzureMonitorConfig, opts ...Option) (*Logger, error) {
+	switch {
+	case cfg.Endpoint == "":
+		return nil, errors.New("azure monitor endpoint not set")
+	case cfg.RuleID == "":
+		return nil, errors.New("azure monitor data collection rule id not set")
+	case cfg.Stream == "":
+		return nil, errors.New("azure monitor stream not set")
+	case cfg.Token == nil:
+		return nil, errors.New("azure monitor token func not set")
+	}
+	batch, err := cfg.Batch.withDefaults(DefaultAzureMonitorMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	// records include their separator, only the opening bracket of the
+	// request body remains to be accounted for
+	batch.MaxBytes--
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+
+	o := newOptions(opts)
+	columns := map[string]string{
+		o.keys.Time:    "TimeGenerated",
+		o.keys.Message: "Message",
+		o.keys.Level:   "Level",
+		o.keys.Error:   "Error",
+	}
+	for k, c := range cfg.Columns {
+		columns[k] = c
+	}
+	a := &azureMonitor{
+		cfg:     cfg,
+		keys:    o.keys,
+		columns: columns,
+		url: strings.TrimRight(cfg.Endpoint, "/") + "/dataCollectionRules/" +
+			url.PathEscape(cfg.RuleID) + "/streams/" + url.PathEscape(cfg.Stream) +
+			"?api-version=" + azureAPIVersion,
+	}
+	return newLogger(newBatchSink(batch, o, a.encode, a.send), o), nil
+}
+
+// azureMonitor encodes and sends batches of records to the Logs ingestion
+// API.
+type azureMonitor struct {
+	cfg     AzureMonitorConfig
+	keys    KeyNames
+	columns map[string]string
+	url     string
+}
+
+// encode renders e as a JSON row followed by a separator.
+func (a *azureMonitor) encode(e *Entry) ([]byte, error) {
+	b, err := json.Marshal(a.row(e))
+	if err != nil {
+		return nil, err
+	}
+	return append(b, ','), nil
+}
+
+// row maps e to the columns of the stream.
+func (a *azureMonitor) row(e *Entry) map[string]interface{} {
+	row := map[string]interface{}{
+		a.columns[a.keys.Time]:    e.Time.UTC().Format(time.RFC3339Nano),
+		a.columns[a.keys.Message]: e.Message,
+		a.columns[a.keys.Level]:   recordLevelString(e.Level),
+	}
+	if e.Level == Error && e.Error != nil {
+		row[a.columns[a.keys.Error]] = e.Error.Error()
+	}
+	var props map[string]interface{}
+	for i := 0; i < len(e.KeyValues); i += 2 {
+		v := pairValue(e.KeyValues, i)
+		k := fmt.Sprint(e.KeyValues[i])
+		if c, ok := a.columns[k]; ok {
+			row[c] = jsonValue(v)
+			continue
+		}
+		if a.cfg.PropertiesColumn == "" {
+			continue
+		}
+		if props == nil {
+			props = make(map[string]interface{})
+		}
+		props[k] = jsonValue(v)
+	}
+	if props != nil {
+		row[a.cfg.PropertiesColumn] = props
+	}
+	return row
+}
+
+// send posts records as a JSON array to the Logs ingestion API. Throttled
+// requests are retried as a whole.
+func (a *azureMonitor) send(ctx context.Context, records [][]byte) ([][]byte, time.Duration, error) {
+	body := bytes.Join(append([][]byte{[]byte("[")}, records...), nil)
+	body[len(body)-1] = ']'
+
+	token, err := a.cfg.Token(ctx, AzureMonitorScope)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to obtain azure monitor token: %w", err)
+	}
+	req, err := newPostRequest(ctx, a.url, body, a.cfg.Compression)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := a.cfg.Client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode < 300 {
+		return nil, 0, nil
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return records, retryAfter(res.Header.Get("Retry-After")), nil
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+	return nil, 0, fmt.Errorf("azure monitor request failed: %s: %s", res.Status, bytes.TrimSpace(msg))
+}
@@ -0,0 +1,96 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithConfigBanner emits a single Info record at construction describing the
+// effective logging configuration: the level, the destinations with their
+// output format, sampling and async mode. This allows support engineers to
+// see from the logs themselves how logging was configured. The record is
+// emitted regardless of the configured level.
+func WithConfigBanner() Option {
+	return func(o *options) {
+		o.banner = true
+	}
+}
+
+// emitBanner emits the configuration banner record.
+func (l *Logger) emitBanner() {
+	sampling := "none"
+	if l.opts.sampler != nil {
+		sampling = fmt.Sprintf("%T", l.opts.sampler)
+	}
+	e := l.entry(Info, "logging configured", nil, []interface{}{
+		"log_level", levelString(l.Level()),
+		"sinks", describeSink(l.sink),
+		"sampling", sampling,
+		"async_queue_size", l.opts.asyncQueueSize,
+	})
+	_ = l.sink.emit(e)
+}
+
+// describeSink returns a textual description of s and the sinks it wraps.
+func describeSink(s sink) string {
+	switch t := s.(type) {
+	case *asyncSink:
+		return "async(" + describeSink(t.sink) + ")"
+	case pipelineSink:
+		return "pipeline(" + describeSink(t.sink) + ")"
+	case levelSink:
+		return levelString(t.lvl) + "(" + describeSink(t.sink) + ")"
+	case dryRunSink:
+		return "dryrun(" + describeSink(t.sink) + ")"
+	case *deferredSink:
+		if target := t.sink(); target != nil {
+			return "deferred(" + describeSink(target) + ")"
+		}
+		return "deferred(unbound)"
+	case teeSink:
+		return "tee(" + describeSinks(t) + ")"
+	case failoverSink:
+		return "failover(" + describeSinks(t) + ")"
+	case *cliSink:
+		return "cli(text, " + describeSink(t.stderr) + ")"
+	case *encoderSink:
+		return fmt.Sprintf("encoder(%T)", t.enc)
+	case kitSink:
+		logger := t.logger
+		if sl, ok := logger.(*syncLogger); ok {
+			logger = sl.logger
+		}
+		switch fmt.Sprintf("%T", logger) {
+		case "*log.logfmtLogger":
+			return "logfmt"
+		case "*log.jsonLogger":
+			return "json"
+		default:
+			return fmt.Sprintf("gokit(%T)", logger)
+		}
+	default:
+		return fmt.Sprintf("%T", s)
+	}
+}
+
+func describeSinks(list []sink) string {
+	res := make([]string, 0, len(list))
+	for _, s := range list {
+		res = append(res, describeSink(s))
+	}
+	return strings.Join(res, ", ")
+}
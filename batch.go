@@ -0,0 +1,83 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+
+	"github.com/tetratelabs/multierror"
+)
+
+// LogBatch validates and emits the provided entries in a single pass. Entries
+// failing validation are skipped and reported in the returned error. Entries
+// without Time set are stamped using the Logger's Clock. Each entry passes the
+// same level override, escalation, level, re-entrancy and sampling checks as
+// records logged through the logging methods, and is mirrored as span event
+// if configured. If the destination supports it, as is the case for
+// NewSyncLogfmt, NewSyncJSON and NewWithEncoder, all records are written with
+// a single writer lock acquisition. This is useful when draining internal
+// buffers or converting bulk events into log lines.
+func (l *Logger) LogBatch(entries []Entry) error {
+	var (
+		mErr    error
+		records = make([]*Entry, 0, len(entries))
+	)
+	if l.opts.reentrancyGuard && l.reentrant() {
+		return nil
+	}
+	for idx, e := range entries {
+		if _, ok := levelToString[e.Level]; !ok || e.Level == None {
+			mErr = multierror.Append(mErr, fmt.Errorf("entry %d: %d is not a valid log level", idx, e.Level))
+			continue
+		}
+		if len(e.KeyValues)%2 != 0 {
+			mErr = multierror.Append(mErr, fmt.Errorf("entry %d: odd number of key-values", idx))
+			continue
+		}
+		if l.metric != nil && e.Level <= Info {
+			l.metric.RecordContext(l.ctx, 1)
+		}
+		lvl := e.Level
+		if override, ok := l.levelOverride(e.KeyValues); ok {
+			lvl = override
+		}
+		lvl, keyValues := l.escalated(lvl, e.Message, e.KeyValues)
+		if !l.Enabled(lvl) {
+			if lvl > None && lvl <= Error && l.opts.suppressed != nil {
+				l.reportSuppressed()
+			}
+			continue
+		}
+		if l.opts.sampler != nil && l.opts.sampler.Decide(lvl, e.Message, keyValues) == SampleDrop {
+			continue
+		}
+		record := l.entry(lvl, e.Message, e.Error, keyValues)
+		if !e.Time.IsZero() {
+			record.Time = e.Time
+		}
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		return mErr
+	}
+	if err := l.sink.emit(records...); err != nil {
+		mErr = multierror.Append(mErr, err)
+	}
+	for _, record := range records {
+		l.mirrorSpanEvent(record)
+	}
+	return mErr
+}
@@ -0,0 +1,313 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/multierror"
+)
+
+// Defaults of BatchConfig.
+const (
+	DefaultBatchSize          = 500
+	DefaultBatchFlushInterval = 5 * time.Second
+	DefaultBatchMaxInFlight   = 1
+	DefaultBatchMaxRetries    = 5
+	DefaultBatchBackoff       = 100 * time.Millisecond
+)
+
+// Errors returned by sinks sending records in batches.
+var (
+	// ErrThrottled is returned if records were still throttled by the remote
+	// end after all retries.
+	ErrThrottled = errors.New("batch throttled")
+	// ErrRecordTooLarge is returned for records exceeding the maximum batch
+	// size in bytes on their own.
+	ErrRecordTooLarge = errors.New("record exceeds maximum batch size")
+)
+
+// BatchConfig configures how remote sinks, such as NewBulk and
+// NewAzureMonitor, group records into requests. Zero values are replaced with
+// defaults.
+type BatchConfig struct {
+	// Size holds the maximum number of records per batch. If not set,
+	// DefaultBatchSize is used.
+	Size int
+	// MaxBytes holds the maximum size of the encoded records of a batch,
+	// before compression. If not set, the default of the sink is used, which
+	// matches the request size limit of the remote end.
+	MaxBytes int
+	// FlushInterval holds the interval at which partial batches are sent. If
+	// not set, DefaultBatchFlushInterval is used. A negative value disables
+	// periodic flushing.
+	FlushInterval time.Duration
+	// MaxInFlight holds the maximum number of batches sent concurrently. If
+	// not set, DefaultBatchMaxInFlight is used. Only with a single batch in
+	// flight, records are guaranteed to arrive in order.
+	MaxInFlight int
+	// MaxRetries holds the number of retries of throttled records. If not
+	// set, DefaultBatchMaxRetries is used.
+	MaxRetries int
+	// Backoff holds the initial delay between retries, doubled on each
+	// attempt, unless the remote end requests a specific delay. If not set,
+	// DefaultBatchBackoff is used.
+	Backoff time.Duration
+}
+
+// Validate returns an error if c holds invalid settings.
+func (c BatchConfig) Validate() error {
+	var mErr error
+	check := func(name string, v int64) {
+		if v < 0 {
+			mErr = multierror.Append(mErr, fmt.Errorf("batch %s must not be negative", name))
+		}
+	}
+	check("size", int64(c.Size))
+	check("max bytes", int64(c.MaxBytes))
+	check("max in flight", int64(c.MaxInFlight))
+	check("max retries", int64(c.MaxRetries))
+	check("backoff", int64(c.Backoff))
+	return mErr
+}
+
+// withDefaults validates c and returns it with its unset fields replaced by
+// their defaults, using maxBytes as default for MaxBytes.
+func (c BatchConfig) withDefaults(maxBytes int) (BatchConfig, error) {
+	if err := c.Validate(); err != nil {
+		return c, err
+	}
+	if c.Size == 0 {
+		c.Size = DefaultBatchSize
+	}
+	if c.MaxBytes == 0 {
+		c.MaxBytes = maxBytes
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = DefaultBatchFlushInterval
+	}
+	if c.MaxInFlight == 0 {
+		c.MaxInFlight = DefaultBatchMaxInFlight
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultBatchMaxRetries
+	}
+	if c.Backoff == 0 {
+		c.Backoff = DefaultBatchBackoff
+	}
+	return c, nil
+}
+
+// batchEncodeFunc renders a single record of a batch.
+type batchEncodeFunc func(e *Entry) ([]byte, error)
+
+// batchSendFunc sends a batch of encoded records. It returns the records to
+// retry, if throttled, optionally with the delay requested by the remote end.
+type batchSendFunc func(ctx context.Context, records [][]byte) (retry [][]byte, delay time.Duration, err error)
+
+// batchSink groups encoded records into batches as configured by BatchConfig
+// and hands them to a batchSendFunc, retrying throttled records. It is
+// registered as Flusher and flushes partial batches periodically until
+// closed.
+type batchSink struct {
+	cfg    BatchConfig
+	encode batchEncodeFunc
+	send   batchSendFunc
+	// inflight limits the number of batches sent concurrently.
+	inflight   chan struct{}
+	deregister func()
+	// stop is closed by close, after which run closes done.
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mtx          sync.Mutex
+	pending      [][]byte
+	pendingBytes int
+}
+
+func newBatchSink(cfg BatchConfig, o *options, encode batchEncodeFunc, send batchSendFunc) *batchSink {
+	s := &batchSink{
+		cfg:      cfg,
+		encode:   encode,
+		send:     send,
+		inflight: make(chan struct{}, cfg.MaxInFlight),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	s.deregister = RegisterFlusher(s)
+	if cfg.FlushInterval > 0 {
+		go s.run(o)
+	} else {
+		close(s.done)
+	}
+	return s
+}
+
+func (s *batchSink) emit(entries ...*Entry) error {
+	var (
+		mErr    error
+		records = make([][]byte, 0, len(entries))
+		batches [][][]byte
+	)
+	for _, e := range entries {
+		record, err := s.encode(e)
+		if err != nil {
+			mErr = multierror.Append(mErr, err)
+			continue
+		}
+		if len(record) > s.cfg.MaxBytes {
+			mErr = multierror.Append(mErr, fmt.Errorf("%w: %d bytes", ErrRecordTooLarge, len(record)))
+			continue
+		}
+		records = append(records, record)
+	}
+
+	s.mtx.Lock()
+	for _, record := range records {
+		if len(s.pending) > 0 && s.pendingBytes+len(record) > s.cfg.MaxBytes {
+			batches = append(batches, s.take())
+		}
+		s.pending = append(s.pending, record)
+		s.pendingBytes += len(record)
+		if len(s.pending) >= s.cfg.Size {
+			batches = append(batches, s.take())
+		}
+	}
+	s.mtx.Unlock()
+
+	for _, batch := range batches {
+		if err := s.sendBatch(context.Background(), batch); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr
+}
+
+// take returns the pending records, resetting the batch. It must be called
+// with s.mtx held.
+func (s *batchSink) take() [][]byte {
+	batch := s.pending
+	s.pending, s.pendingBytes = nil, 0
+	return batch
+}
+
+// Flush implements Flusher. It sends the pending records and waits for the
+// batches in flight.
+func (s *batchSink) Flush(ctx context.Context) error {
+	s.mtx.Lock()
+	batch := s.take()
+	s.mtx.Unlock()
+
+	var mErr error
+	if len(batch) > 0 {
+		if err := s.sendBatch(ctx, batch); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	// wait for the batches sent by other goroutines
+	for i := 0; i < cap(s.inflight); i++ {
+		select {
+		case s.inflight <- struct{}{}:
+			defer func() { <-s.inflight }()
+		case <-ctx.Done():
+			return multierror.Append(mErr, ctx.Err())
+		}
+	}
+	return mErr
+}
+
+// sendBatch sends batch, retrying throttled records with backoff.
+func (s *batchSink) sendBatch(ctx context.Context, batch [][]byte) error {
+	select {
+	case s.inflight <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-s.inflight }()
+
+	var mErr error
+	backoff := s.cfg.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, delay, err := s.send(ctx, batch)
+		if err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+		if len(retry) == 0 {
+			return mErr
+		}
+		if attempt == s.cfg.MaxRetries {
+			return multierror.Append(mErr,
+				fmt.Errorf("%w: %d records dropped", ErrThrottled, len(retry)))
+		}
+		if delay <= 0 {
+			delay = backoff
+			backoff *= 2
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return multierror.Append(mErr, ctx.Err())
+		}
+		batch = retry
+	}
+}
+
+// run flushes partial batches periodically until the sink is closed.
+func (s *batchSink) run(o *options) {
+	defer close(s.done)
+	t := time.NewTicker(s.cfg.FlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := s.Flush(context.Background()); err != nil {
+				o.reportError(err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// close stops periodic flushing, deregisters the sink as Flusher and sends
+// the pending records.
+func (s *batchSink) close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		s.deregister()
+		err = s.Flush(context.Background())
+	})
+	return err
+}
+
+// retryAfter returns the delay of a Retry-After header holding seconds, or 0
+// if not set.
+func retryAfter(v string) time.Duration {
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
@@ -0,0 +1,185 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tetratelabs/multierror"
+)
+
+// batchRecorder records the batches sent by a batchSink.
+type batchRecorder struct {
+	mtx     sync.Mutex
+	batches [][][]byte
+	// throttle holds the number of attempts to throttle.
+	throttle int
+}
+
+func (r *batchRecorder) send(_ context.Context, records [][]byte) ([][]byte, time.Duration, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.throttle > 0 {
+		r.throttle--
+		return records, 0, nil
+	}
+	r.batches = append(r.batches, records)
+	return nil, 0, nil
+}
+
+func (r *batchRecorder) sizes() []int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	res := make([]int, 0, len(r.batches))
+	for _, b := range r.batches {
+		res = append(res, len(b))
+	}
+	return res
+}
+
+func encodeMessage(e *Entry) ([]byte, error) {
+	return []byte(e.Message), nil
+}
+
+func newTestBatchSink(t *testing.T, cfg BatchConfig, r *batchRecorder) *batchSink {
+	t.Helper()
+	cfg, err := cfg.withDefaults(1 << 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newBatchSink(cfg, newOptions(nil), encodeMessage, r.send)
+}
+
+func equalSizes(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasError reports whether err, or one of the errors it aggregates, matches
+// target.
+func hasError(err, target error) bool {
+	if mErr, ok := multierror.Flatten(err).(*multierror.Error); ok {
+		for _, e := range mErr.Errors {
+			if errors.Is(e, target) {
+				return true
+			}
+		}
+		return false
+	}
+	return errors.Is(err, target)
+}
+
+func TestBatchSinkSize(t *testing.T) {
+	r := &batchRecorder{}
+	s := newTestBatchSink(t, BatchConfig{Size: 3, FlushInterval: -1}, r)
+
+	for i := 0; i < 7; i++ {
+		if err := s.emit(&Entry{Message: "record"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := r.sizes(); !equalSizes(got, []int{3, 3}) {
+		t.Errorf("expected full batches only, got %v", got)
+	}
+	if err := s.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.sizes(); !equalSizes(got, []int{3, 3, 1}) {
+		t.Errorf("expected the partial batch after flush, got %v", got)
+	}
+}
+
+func TestBatchSinkMaxBytes(t *testing.T) {
+	r := &batchRecorder{}
+	s := newTestBatchSink(t, BatchConfig{MaxBytes: 10, FlushInterval: -1}, r)
+
+	err := s.emit(&Entry{Message: "1234"}, &Entry{Message: "5678"}, &Entry{Message: "90"},
+		&Entry{Message: "too large record"})
+	if !hasError(err, ErrRecordTooLarge) {
+		t.Errorf("expected %v, got %v", ErrRecordTooLarge, err)
+	}
+	if err = s.emit(&Entry{Message: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.sizes(); !equalSizes(got, []int{3}) {
+		t.Errorf("expected a batch of 10 bytes, got %v", got)
+	}
+}
+
+func TestBatchSinkRetry(t *testing.T) {
+	r := &batchRecorder{throttle: 2}
+	s := newTestBatchSink(t, BatchConfig{Size: 1, FlushInterval: -1, Backoff: time.Millisecond}, r)
+
+	if err := s.emit(&Entry{Message: "record"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.sizes(); !equalSizes(got, []int{1}) {
+		t.Errorf("expected the batch to be retried, got %v", got)
+	}
+
+	r.throttle = 10
+	err := s.emit(&Entry{Message: "record"})
+	if !hasError(err, ErrThrottled) {
+		t.Errorf("expected %v, got %v", ErrThrottled, err)
+	}
+}
+
+func TestBatchSinkPeriodicFlush(t *testing.T) {
+	r := &batchRecorder{}
+	s := newTestBatchSink(t, BatchConfig{FlushInterval: time.Millisecond}, r)
+	defer func() { _ = s.close() }()
+
+	if err := s.emit(&Entry{Message: "record"}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(r.sizes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch not flushed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBatchSinkClose(t *testing.T) {
+	r := &batchRecorder{}
+	s := newTestBatchSink(t, BatchConfig{}, r)
+
+	if err := s.emit(&Entry{Message: "record"}, &Entry{Message: "record"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.sizes(); !equalSizes(got, []int{2}) {
+		t.Errorf("expected the pending records to be sent on close, got %v", got)
+	}
+	if err := s.close(); err != nil {
+		t.Errorf("expected repeated close to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+
+	"github.com/tetratelabs/telemetry"
+)
+
+// BoostFunc reports whether the records of a Logger with the provided Context
+// attached should be emitted up to Debug level regardless of the configured
+// log level.
+type BoostFunc func(ctx context.Context) bool
+
+// ctxDebugKey is the Context key of the debug flag.
+type ctxDebugKey struct{}
+
+// WithDebugBoost enables per-request verbose logging. Records up to Debug
+// level are emitted, regardless of the configured log level, for Loggers
+// whose attached Context satisfies f. If f is nil, the debug flag set through
+// ContextWithDebug is used. The BoostFunc is only consulted for records which
+// would otherwise be suppressed by the level configuration.
+func WithDebugBoost(f BoostFunc) Option {
+	return func(o *options) {
+		if f == nil {
+			f = HasDebugFlag
+		}
+		o.boost = f
+	}
+}
+
+// ContextWithDebug returns a Context carrying the debug flag, boosting the
+// records of Loggers configured with WithDebugBoost to Debug level.
+func ContextWithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxDebugKey{}, true)
+}
+
+// HasDebugFlag is a BoostFunc reporting whether the Context carries the debug
+// flag set through ContextWithDebug.
+func HasDebugFlag(ctx context.Context) bool {
+	flag, _ := ctx.Value(ctxDebugKey{}).(bool)
+	return flag
+}
+
+// BoostOnKeyValue returns a BoostFunc reporting whether the key-value pairs
+// found in the Context hold key with one of the provided values. Combined with
+// ContextFromHTTPHeaders or ContextFromMetadata this allows clients to enable
+// verbose logging through a request header, e.g. "x-debug: true". If no values
+// are provided, the presence of key suffices.
+func BoostOnKeyValue(key string, values ...string) BoostFunc {
+	return func(ctx context.Context) bool {
+		keyValues := telemetry.KeyValuesFromContext(ctx)
+		for i := 0; i+1 < len(keyValues); i += 2 {
+			if k, ok := keyValues[i].(string); !ok || k != key {
+				continue
+			}
+			if len(values) == 0 {
+				return true
+			}
+			v := fmt.Sprint(keyValues[i+1])
+			for _, value := range values {
+				if v == value {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// boosted reports whether a record at lvl is to be emitted regardless of the
+// configured log level due to the debug boost.
+func (l *Logger) boosted(lvl Level) bool {
+	return l.opts.boost != nil && lvl > None && lvl <= Debug && l.opts.boost(l.ctx)
+}
+
+// BoostOnTraceID returns a BoostFunc, for use with WithDebugBoost, enabling
+// Debug records for a stable slice of traffic: requests whose trace id, as
+// returned by traceID, hashes into the provided percentage of buckets. As the
+// decision only depends on the trace id, all services handling a sampled
+// request log it verbosely. The percentage is clamped to the range of 0 to
+// 100, with NaN treated as 0.
+// Tracing libraries can be bridged through traceID, e.g. for OpenTelemetry:
+//
+//	func(ctx context.Context) string {
+//		return trace.SpanContextFromContext(ctx).TraceID().String()
+//	}
+//
+// See TraceIDFromKeyValue for trace ids propagated as key-value pairs.
+func BoostOnTraceID(traceID func(ctx context.Context) string, percent float64) BoostFunc {
+	switch {
+	case math.IsNaN(percent) || percent < 0:
+		percent = 0
+	case percent > 100:
+		percent = 100
+	}
+	threshold := uint64(percent * 100)
+	return func(ctx context.Context) bool {
+		id := traceID(ctx)
+		if id == "" {
+			return false
+		}
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(id))
+		return h.Sum64()%10000 < threshold
+	}
+}
+
+// TraceIDFromKeyValue returns a trace id extractor for BoostOnTraceID,
+// returning the value of key found in the key-value pairs of the Context.
+func TraceIDFromKeyValue(key string) func(ctx context.Context) string {
+	return func(ctx context.Context) string {
+		keyValues := telemetry.KeyValuesFromContext(ctx)
+		for i := 0; i+1 < len(keyValues); i += 2 {
+			if k, ok := keyValues[i].(string); ok && k == key {
+				return fmt.Sprint(keyValues[i+1])
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "sync"
+
+// BufferMode determines when the records held by a RecordBuffer are emitted.
+type BufferMode int
+
+// Available buffer modes.
+const (
+	// EmitAlways emits the buffered records on Flush.
+	EmitAlways BufferMode = iota
+	// EmitOnError only emits the buffered records on Flush if a non-nil error
+	// is provided or an Error record was buffered.
+	EmitOnError
+)
+
+// RecordBuffer holds the records of a single request, emitting them as one
+// grouped block on Flush. This drastically reduces log volume while
+// preserving full detail for failing requests. To also capture Debug records
+// of failing requests, combine with WithDetachedLevel and SetLevel.
+type RecordBuffer struct {
+	mtx     sync.Mutex
+	sink    sink
+	mode    BufferMode
+	size    int
+	entries []*Entry
+	failed  bool
+	flushed bool
+}
+
+// Buffered returns a Logger whose records are held in the returned
+// RecordBuffer until Flush is called. If size is larger than zero, only the
+// last size records are retained. The typical use is a request-scoped Logger
+// created by middleware, which calls Flush with the outcome of the request.
+func (l *Logger) Buffered(mode BufferMode, size int) (*Logger, *RecordBuffer) {
+	b := &RecordBuffer{
+		sink: l.sink,
+		mode: mode,
+		size: size,
+	}
+	newLogger := l.clone(0)
+	newLogger.sink = b
+
+	return newLogger, b
+}
+
+func (b *RecordBuffer) emit(entries ...*Entry) error {
+	b.mtx.Lock()
+	if b.flushed {
+		b.mtx.Unlock()
+		return b.sink.emit(entries...)
+	}
+	defer b.mtx.Unlock()
+
+	for _, e := range entries {
+		if e.Level > None && e.Level <= Error {
+			b.failed = true
+		}
+	}
+	b.entries = append(b.entries, entries...)
+	if b.size > 0 && len(b.entries) > b.size {
+		b.entries = append(b.entries[:0], b.entries[len(b.entries)-b.size:]...)
+	}
+	return nil
+}
+
+// Flush emits the buffered records as one grouped block, honoring the
+// BufferMode given the provided request error. Records emitted after Flush are
+// passed through directly.
+func (b *RecordBuffer) Flush(err error) error {
+	b.mtx.Lock()
+	entries := b.entries
+	emit := b.mode == EmitAlways || err != nil || b.failed
+	b.entries, b.flushed = nil, true
+	b.mtx.Unlock()
+
+	if !emit || len(entries) == 0 {
+		return nil
+	}
+	return b.sink.emit(entries...)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+
+	"github.com/tetratelabs/telemetry"
+)
+
+// LoggerBuilder accumulates the Context, Metric and key-value pairs of a
+// Logger without creating intermediate Loggers. This allows request
+// middleware to assemble a request-scoped Logger with a single allocation
+// instead of chaining Context, Metric and With.
+//
+// A LoggerBuilder is a value type, intended to be used in a single chain of
+// method calls. Values should not be reused after calling one of its methods.
+type LoggerBuilder struct {
+	parent *Logger
+	ctx    context.Context
+	metric telemetry.Metric
+	args   []interface{}
+}
+
+// Builder returns a LoggerBuilder using l as its starting point.
+func (l *Logger) Builder() LoggerBuilder {
+	return LoggerBuilder{
+		parent: l,
+		ctx:    l.ctx,
+		metric: l.metric,
+	}
+}
+
+// Context sets the Context of the Logger to build.
+func (b LoggerBuilder) Context(ctx context.Context) LoggerBuilder {
+	b.ctx = b.parent.opts.checkContext(ctx)
+	return b
+}
+
+// Metric sets the Metric of the Logger to build.
+func (b LoggerBuilder) Metric(m telemetry.Metric) LoggerBuilder {
+	b.metric = b.parent.scopedMetric(m)
+	return b
+}
+
+// With adds the provided key-value pairs to the Logger to build.
+func (b LoggerBuilder) With(keyValues ...interface{}) LoggerBuilder {
+	if len(keyValues) == 0 {
+		return b
+	}
+	if b.args == nil {
+		b.args = make([]interface{}, len(b.parent.args), len(b.parent.args)+len(keyValues)+1)
+		copy(b.args, b.parent.args)
+	}
+	b.args = appendKeyValues(b.args, b.parent.opts.validateKeyValues(keyValues))
+	return b
+}
+
+// Build returns the Logger holding all accumulated settings.
+func (b LoggerBuilder) Build() *Logger {
+	if b.args == nil {
+		b.args = make([]interface{}, len(b.parent.args), len(b.parent.args))
+		copy(b.args, b.parent.args)
+	}
+	newLogger := *b.parent
+	newLogger.pooled = false
+	newLogger.args = b.args
+	newLogger.ctx = b.ctx
+	newLogger.metric = b.metric
+
+	return &newLogger
+}
@@ -0,0 +1,218 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/tetratelabs/multierror"
+)
+
+// DefaultBulkMaxBytes holds the default maximum size of a _bulk request,
+// within the range recommended by Elasticsearch.
+const DefaultBulkMaxBytes = 5 << 20
+
+// BulkConfig configures a Logger writing to the _bulk API of Elasticsearch or
+// OpenSearch.
+type BulkConfig struct {
+	// URL holds the base URL of the cluster, e.g. https://es.example.com:9200.
+	URL string
+	// Index holds the name of the index records are written to. Placeholders
+	// in the form of {layout} are replaced with the record time in UTC,
+	// formatted using the Go time layout, e.g. "logs-{2006.01.02}".
+	Index string
+	// Client holds the http.Client used, which carries the TLS, proxy and
+	// connection pooling settings for the cluster. If nil, http.DefaultClient
+	// is used.
+	Client *http.Client
+	// Header holds additional request headers, e.g. Authorization.
+	Header http.Header
+	// Encoder renders the documents. If nil, NewECSEncoder is used.
+	Encoder Encoder
+	// Compression compresses the request bodies if set, e.g. using
+	// GzipCompressor.
+	Compression Compressor
+	// Batch configures the batching of records. If Batch.MaxBytes is not
+	// set, DefaultBulkMaxBytes is used.
+	Batch BatchConfig
+}
+
+// NewBulk returns a new telemetry.Logger implementation writing records to
+// the _bulk API of Elasticsearch or OpenSearch, for deployments without a log
+// shipper. Records are sent in batches as configured by BatchConfig. Each
+// document is assigned an id derived from a per Logger prefix and a sequence
+// number, and is written using the create action, so retried records are
+// never indexed twice. Records rejected with status 429 are retried with
+// exponential backoff. Failures of periodic flushes are reported to the
+// ErrorHandler. As records are sent synchronously once a batch is full,
+// combining it with WithAsync is recommended. Use Close to send the pending
+// records and stop periodic flushing once the Logger is no longer used.
+func NewBulk(cfg BulkConfig, opts ...Option) (*Logger, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("bulk URL not set")
+	}
+	if cfg.Index == "" {
+		return nil, errors.New("bulk index not set")
+	}
+	batch, err := cfg.Batch.withDefaults(DefaultBulkMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	if cfg.Encoder == nil {
+		cfg.Encoder = NewECSEncoder()
+	}
+	prefix := make([]byte, 8)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("unable to create document id prefix: %w", err)
+	}
+
+	o := newOptions(opts)
+	b := &bulk{
+		cfg:    cfg,
+		url:    strings.TrimRight(cfg.URL, "/") + "/_bulk",
+		prefix: hex.EncodeToString(prefix),
+	}
+	return newLogger(newBatchSink(batch, o, b.encode, b.send), o), nil
+}
+
+// bulk encodes and sends records for the _bulk API.
+type bulk struct {
+	cfg    BulkConfig
+	url    string
+	prefix string
+	seq    uint64
+}
+
+// encode renders e into its action line followed by the document.
+func (b *bulk) encode(e *Entry) ([]byte, error) {
+	action, err := json.Marshal(map[string]interface{}{
+		"create": map[string]string{
+			"_index": bulkIndex(b.cfg.Index, e.Time),
+			"_id":    b.prefix + "-" + strconv.FormatUint(atomic.AddUint64(&b.seq, 1), 10),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	record := bytes.NewBuffer(append(action, '\n'))
+	if err = b.cfg.Encoder.Encode(record, e); err != nil {
+		return nil, err
+	}
+	if r := record.Bytes(); r[len(r)-1] != '\n' {
+		record.WriteByte('\n')
+	}
+	return record.Bytes(), nil
+}
+
+// bulkResponse holds the relevant parts of a _bulk API response.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// send posts a single _bulk request, returning the records to retry.
+func (b *bulk) send(ctx context.Context, records [][]byte) ([][]byte, time.Duration, error) {
+	req, err := newPostRequest(ctx, b.url, bytes.Join(records, nil), b.cfg.Compression)
+	if err != nil {
+		return nil, 0, err
+	}
+	for k, v := range b.cfg.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	res, err := b.cfg.Client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		return records, retryAfter(res.Header.Get("Retry-After")), nil
+	}
+	if res.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return nil, 0, fmt.Errorf("bulk request failed: %s: %s", res.Status, bytes.TrimSpace(msg))
+	}
+
+	var br bulkResponse
+	if err = json.NewDecoder(res.Body).Decode(&br); err != nil {
+		return nil, 0, fmt.Errorf("invalid bulk response: %w", err)
+	}
+	if !br.Errors {
+		return nil, 0, nil
+	}
+	var (
+		mErr  error
+		retry [][]byte
+	)
+	for idx, item := range br.Items {
+		for _, result := range item {
+			switch {
+			case result.Status == http.StatusTooManyRequests && idx < len(records):
+				retry = append(retry, records[idx])
+			case result.Status == http.StatusConflict:
+				// already indexed by a previous attempt
+			case result.Status >= 300:
+				mErr = multierror.Append(mErr, fmt.Errorf("bulk item %d failed: %d: %s",
+					idx, result.Status, result.Error))
+			}
+		}
+	}
+	return retry, 0, mErr
+}
+
+// bulkIndex returns index with its {layout} placeholders replaced with t in
+// UTC, formatted using the layout.
+func bulkIndex(index string, t time.Time) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(index, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(index[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(index[:start])
+		b.WriteString(t.UTC().Format(index[start+1 : start+end]))
+		index = index[start+end+1:]
+	}
+	b.WriteString(index)
+	return b.String()
+}
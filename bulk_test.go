@@ -0,0 +1,86 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bulkServer is a fake _bulk endpoint counting the received documents.
+type bulkServer struct {
+	mtx      sync.Mutex
+	docs     int
+	requests int
+	// throttle holds the number of requests to reject with status 429.
+	throttle int
+}
+
+func (b *bulkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.requests++
+	if b.throttle > 0 {
+		b.throttle--
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	lines := 0
+	for sc := bufio.NewScanner(r.Body); sc.Scan(); {
+		lines++
+	}
+	b.docs += lines / 2
+	_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+}
+
+func (b *bulkServer) counts() (docs, requests int) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return b.docs, b.requests
+}
+
+func TestBulk(t *testing.T) {
+	b := &bulkServer{throttle: 1}
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+
+	l, err := NewBulk(BulkConfig{
+		URL:   srv.URL,
+		Index: "logs-{2006.01.02}",
+		Batch: BatchConfig{Size: 2, Backoff: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		l.Info("record", "i", i)
+	}
+	if docs, requests := b.counts(); docs != 2 || requests != 2 {
+		t.Errorf("expected 2 documents in 2 requests, got %d in %d", docs, requests)
+	}
+	if err = l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if docs, requests := b.counts(); docs != 3 || requests != 3 {
+		t.Errorf("expected 3 documents in 3 requests after close, got %d in %d", docs, requests)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "runtime"
+
+// maxCallDepth holds the maximum call stack depth reported by call_depth.
+const maxCallDepth = 1 << 16
+
+// WithCallDepth adds a call_depth field to each record holding the depth of
+// the call stack at the calling site. This helps diagnosing runaway recursion
+// and re-entrant logging loops.
+func WithCallDepth() Option {
+	return func(o *options) {
+		o.callDepth = true
+	}
+}
+
+// WithCallerChain adds a caller_chain field to each record holding the
+// innermost n frames of the call stack, starting at the calling site.
+func WithCallerChain(n int) Option {
+	return func(o *options) {
+		o.callerChain = n
+	}
+}
+
+// callDepth returns the depth of the call stack. The argument skip is the
+// number of stack frames to skip, with 0 identifying the caller of callDepth.
+func callDepth(skip int) int {
+	pc := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(skip+2, pc)
+		if n < len(pc) || len(pc) >= maxCallDepth {
+			return n
+		}
+		pc = make([]uintptr, 2*len(pc))
+	}
+}
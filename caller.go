@@ -0,0 +1,44 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// WithCaller adds a caller field to each record holding the file name and
+// line of the calling site, e.g. caller=server.go:123. The argument skip is
+// the number of additional frames to skip, allowing helper functions wrapping
+// the Logger to attribute records to their own callers. Unlike Go kit's
+// log.DefaultCaller, the frame is computed taking the wrapper methods of this
+// package into account.
+func WithCaller(skip int) Option {
+	return func(o *options) {
+		o.caller = true
+		o.callerSkip = skip
+	}
+}
+
+// caller returns the file:line of the calling site. The argument skip is the
+// number of stack frames to skip, with 0 identifying the caller of caller.
+func caller(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "???"
+	}
+	return filepath.Base(file) + ":" + strconv.Itoa(line)
+}
@@ -0,0 +1,119 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CEFConfig configures the Encoder returned by NewCEFEncoder.
+type CEFConfig struct {
+	// Vendor holds the Device Vendor header.
+	Vendor string
+	// Product holds the Device Product header.
+	Product string
+	// Version holds the Device Version header.
+	Version string
+	// ClassIDKey optionally holds the key of the key-value pair holding the
+	// Device Event Class ID header. If not set, or not found in a record, the
+	// level of the record is used.
+	ClassIDKey string
+	// Extensions maps record keys to CEF extension keys, e.g. "client_ip" to
+	// "src". Unmapped keys are emitted as custom extensions, stripped of
+	// characters other than letters and digits.
+	Extensions map[string]string
+}
+
+// NewCEFEncoder returns an Encoder emitting records in the ArcSight Common
+// Event Format (CEF) for SIEM ingestion. The message is emitted as the Name
+// header, the record time as the rt extension and the error of Error records
+// as the msg extension. As SIEM ingestion typically only concerns Error
+// records, use it with a Sink of NewMulti holding Level Error.
+func NewCEFEncoder(cfg CEFConfig) Encoder {
+	header := "CEF:0|" + cefHeader(cfg.Vendor) + "|" + cefHeader(cfg.Product) +
+		"|" + cefHeader(cfg.Version) + "|"
+	return EncoderFunc(func(w io.Writer, e *Entry) error {
+		classID := levelString(e.Level)
+		var ext strings.Builder
+		ext.WriteString("rt=")
+		ext.WriteString(strconv.FormatInt(e.Time.UnixNano()/int64(1e6), 10))
+		if e.Level == Error && e.Error != nil {
+			ext.WriteString(" msg=")
+			ext.WriteString(cefExtension(e.Error.Error()))
+		}
+		for i := 0; i < len(e.KeyValues); i += 2 {
+			k := fmt.Sprint(e.KeyValues[i])
+			v := fmt.Sprint(pairValue(e.KeyValues, i))
+			if k == cfg.ClassIDKey && cfg.ClassIDKey != "" {
+				classID = v
+				continue
+			}
+			if mapped, ok := cfg.Extensions[k]; ok {
+				k = mapped
+			} else if k = cefExtensionKey(k); k == "" {
+				continue
+			}
+			ext.WriteByte(' ')
+			ext.WriteString(k)
+			ext.WriteByte('=')
+			ext.WriteString(cefExtension(v))
+		}
+		_, err := fmt.Fprintf(w, "%s%s|%s|%d|%s\n", header,
+			cefHeader(classID), cefHeader(e.Message), cefSeverity(e.Level), ext.String())
+		return err
+	})
+}
+
+// cefSeverity returns the CEF severity, ranging from 0 to 10, of lvl.
+func cefSeverity(lvl Level) int {
+	switch {
+	case lvl == Error:
+		return 8
+	case lvl == Warn:
+		return 5
+	case lvl == Info:
+		return 3
+	default:
+		return 1
+	}
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+// cefHeader escapes s for use in a CEF header.
+func cefHeader(s string) string {
+	return cefHeaderEscaper.Replace(s)
+}
+
+// cefExtension escapes s for use as a CEF extension value.
+func cefExtension(s string) string {
+	return cefExtensionEscaper.Replace(s)
+}
+
+// cefExtensionKey strips key of characters not allowed in extension keys.
+func cefExtensionKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, key)
+}
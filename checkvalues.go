@@ -0,0 +1,133 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-kit/log"
+)
+
+// Invalid replaces values rejected by the With validation.
+const Invalid = "(INVALID)"
+
+// maxValidationDepth holds the maximum depth at which nested values are
+// inspected for cycles.
+const maxValidationDepth = 32
+
+// WithValueValidation validates the key-value pairs provided to With once,
+// instead of having encoders trip over them on every emitted record. Pairs
+// with non-string keys, which are dropped by With, are reported, with keys of
+// unhashable types, such as slices and maps, reported as such. Channels,
+// functions not wrapped as log.Valuer (see Lazy) and cyclic structures are
+// replaced by Invalid and reported. Unhashable values are accepted, as values
+// are only encoded and never used as map keys. Problems are reported to the
+// ErrorHandler.
+func WithValueValidation() Option {
+	return func(o *options) {
+		o.validate = true
+	}
+}
+
+// Lazy wraps f as a Go kit log.Valuer, evaluated for each emitted record.
+func Lazy(f func() interface{}) log.Valuer {
+	return f
+}
+
+// validateKeyValues returns keyValues with its invalid values replaced by
+// Invalid if validation is enabled. Dropped keys and invalid values are
+// reported to the ErrorHandler. The provided slice is left untouched.
+func (o *options) validateKeyValues(keyValues []interface{}) []interface{} {
+	if !o.validate {
+		return keyValues
+	}
+	res := make([]interface{}, len(keyValues))
+	copy(res, keyValues)
+	for i := 0; i < len(res); i += 2 {
+		switch res[i].(type) {
+		case string, levelOverrideKey:
+		default:
+			if t := reflect.TypeOf(res[i]); t != nil && !t.Comparable() {
+				o.reportError(fmt.Errorf("key of unhashable type %T is dropped", res[i]))
+				continue
+			}
+			o.reportError(fmt.Errorf("key %v of type %T is not a string and is dropped", res[i], res[i]))
+			continue
+		}
+		if i+1 == len(res) {
+			break
+		}
+		if _, ok := res[i+1].(log.Valuer); ok {
+			continue
+		}
+		if err := validateValue(reflect.ValueOf(res[i+1]), nil, 0); err != nil {
+			o.reportError(fmt.Errorf("value of key %v: %w", res[i], err))
+			res[i+1] = Invalid
+		}
+	}
+	return res
+}
+
+// validateValue returns an error if v can't be sensibly encoded. The pointers
+// on the path to v are held by seen.
+func validateValue(v reflect.Value, seen map[uintptr]struct{}, depth int) error {
+	if !v.IsValid() || depth > maxValidationDepth {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() != reflect.Slice || v.Len() > 0 {
+			ptr := v.Pointer()
+			if _, ok := seen[ptr]; ok {
+				return fmt.Errorf("cyclic structure of type %s", v.Type())
+			}
+			if seen == nil {
+				seen = make(map[uintptr]struct{})
+			}
+			seen[ptr] = struct{}{}
+			defer delete(seen, ptr)
+		}
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return validateValue(v.Elem(), seen, depth+1)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := validateValue(v.Field(i), seen, depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateValue(v.Index(i), seen, depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := validateValue(iter.Value(), seen, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "fmt"
+
+// ClampPolicy determines how SetLevel handles levels which do not match one of
+// the named levels.
+type ClampPolicy int
+
+// Available clamp policies.
+const (
+	// ClampNearest snaps levels to the nearest named level at or below the
+	// provided level. Debug verbosity levels, up to Debug + MaxVerbosity, are
+	// retained to support V. This is the default policy.
+	ClampNearest ClampPolicy = iota
+	// ClampStrict rejects levels which are neither named levels nor Debug
+	// verbosity levels, leaving the current level untouched.
+	ClampStrict
+	// ClampNone stores levels verbatim, allowing custom intermediate levels.
+	// Records are emitted if their level is at or below the stored level.
+	// See SetRawLevel for bypassing the policy of a Logger.
+	ClampNone
+)
+
+// WithClampPolicy sets the ClampPolicy applied by SetLevel.
+func WithClampPolicy(p ClampPolicy) Option {
+	return func(o *options) {
+		o.clampPolicy = p
+	}
+}
+
+// clamp returns the level to apply for lvl.
+func (p ClampPolicy) clamp(lvl Level) (Level, error) {
+	switch p {
+	case ClampNone:
+		if lvl < None {
+			return None, nil
+		}
+		return lvl, nil
+	case ClampStrict:
+		if _, ok := levelToString[lvl]; ok || (lvl > Debug && lvl <= Debug+MaxVerbosity) {
+			return lvl, nil
+		}
+		return None, fmt.Errorf("%d is not a valid log level", lvl)
+	default:
+		switch {
+		case lvl <= None:
+			return None, nil
+		case lvl < Warn:
+			return Error, nil
+		case lvl < Info:
+			return Warn, nil
+		case lvl < Debug:
+			return Info, nil
+		case lvl <= Debug+MaxVerbosity:
+			return lvl, nil
+		case lvl < Trace:
+			return Debug + MaxVerbosity, nil
+		default:
+			return Trace, nil
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"io"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/tetratelabs/multierror"
+)
+
+// NewCLI returns a new telemetry.Logger implementation for command-line
+// tools. Info records are rendered as plain human readable text to stdout,
+// omitting all key-value pairs, while all other records keep their structured
+// logfmt output on stderr. This allows the same Logger to serve both user
+// facing output and diagnostics.
+func NewCLI(stdout, stderr io.Writer, opts ...Option) *Logger {
+	o := newOptions(opts)
+	return newLogger(&cliSink{
+		stdout: o.measure(stdout),
+		stderr: newKitSink(newSyncLogger(log.NewLogfmtLogger(o.measure(stderr))), o),
+	}, o)
+}
+
+// cliSink writes Info records as plain text to stdout and all other records
+// to stderr.
+type cliSink struct {
+	mtx    sync.Mutex
+	stdout io.Writer
+	stderr sink
+}
+
+func (s *cliSink) emit(entries ...*Entry) error {
+	var mErr error
+	for _, e := range entries {
+		if e.Level != Info {
+			if err := s.stderr.emit(e); err != nil {
+				mErr = multierror.Append(mErr, err)
+			}
+			continue
+		}
+		s.mtx.Lock()
+		_, err := io.WriteString(s.stdout, e.Message+"\n")
+		s.mtx.Unlock()
+		if err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr
+}
+
+// VerbosityLevel maps common command-line conventions onto a Level: quiet
+// (-q) results in Error, no verbose flags in Info, -v in Debug and each
+// additional v raises the Debug verbosity level, e.g. -vvv results in V(2),
+// up to MaxVerbosity. Quiet takes precedence over verbose.
+func VerbosityLevel(quiet bool, verbose int) Level {
+	switch {
+	case quiet:
+		return Error
+	case verbose <= 0:
+		return Info
+	case verbose > MaxVerbosity+1:
+		return Debug + MaxVerbosity
+	default:
+		return Debug + Level(verbose-1)
+	}
+}
+
+// SetVerbosity applies the Level resulting from the provided command-line
+// conventions to the default logger and all registered scopes. See
+// VerbosityLevel for the mapping.
+func (s *ScopeManager) SetVerbosity(quiet bool, verbose int) {
+	s.SetDefaultOutputLevel(VerbosityLevel(quiet, verbose))
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"errors"
+
+	"github.com/tetratelabs/multierror"
+)
+
+// ErrClosed is returned when emitting records to a closed sink.
+var ErrClosed = errors.New("logger closed")
+
+// Close releases the resources held by the destination of the Logger, such
+// as the background goroutine of async mode, after draining the buffered
+// records. It also removes the destination from the Flusher registry. As the
+// destination is shared with all Loggers derived from l, Close must only be
+// called once these are no longer used, typically at shutdown. Records logged
+// after Close are dropped. Writers and Go kit loggers provided by the caller
+// are not closed.
+func (l *Logger) Close() error {
+	return closeSink(l.sink)
+}
+
+// closer is implemented by sinks holding resources.
+type closer interface {
+	close() error
+}
+
+// closeSink closes s and the sinks it wraps or combines.
+func closeSink(s sink) error {
+	switch t := s.(type) {
+	case closer:
+		return t.close()
+	case pipelineSink:
+		return closeSink(t.sink)
+	case levelSink:
+		return closeSink(t.sink)
+	case dryRunSink:
+		return closeSink(t.sink)
+	case teeSink:
+		return closeSinkList(t)
+	case failoverSink:
+		return closeSinkList(t)
+	default:
+		return nil
+	}
+}
+
+func closeSinkList(list []sink) error {
+	var mErr error
+	for _, s := range list {
+		if err := closeSink(s); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr
+}
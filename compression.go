@@ -0,0 +1,75 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+)
+
+// Compressor compresses the request bodies of network sinks, such as NewBulk
+// and NewAzureMonitor. Implementations wrapping other algorithms, e.g. zstd or
+// snappy, can be provided if supported by the remote end.
+type Compressor interface {
+	// ContentEncoding returns the value of the Content-Encoding header of
+	// compressed requests.
+	ContentEncoding() string
+	// Compress returns a writer compressing to w.
+	Compress(w io.Writer) (io.WriteCloser, error)
+}
+
+// GzipCompressor returns a Compressor using gzip with the provided
+// compression level, e.g. gzip.DefaultCompression.
+func GzipCompressor(level int) Compressor {
+	return gzipCompressor(level)
+}
+
+type gzipCompressor int
+
+func (gzipCompressor) ContentEncoding() string { return "gzip" }
+
+func (c gzipCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, int(c))
+}
+
+// newPostRequest returns a POST request of body, compressed using c if not
+// nil.
+func newPostRequest(ctx context.Context, url string, body []byte, c Compressor) (*http.Request, error) {
+	if c != nil {
+		var buf bytes.Buffer
+		w, err := c.Compress(&buf)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = w.Write(body); err != nil {
+			return nil, err
+		}
+		if err = w.Close(); err != nil {
+			return nil, err
+		}
+		body = buf.Bytes()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		req.Header.Set("Content-Encoding", c.ContentEncoding())
+	}
+	return req, nil
+}
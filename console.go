@@ -0,0 +1,250 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// consoleMessageWidth holds the column width messages are padded to, aligning
+// the key-value pairs of consecutive records.
+const consoleMessageWidth = 40
+
+// ANSI escape sequences used by the console format.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+)
+
+// WithColor enables or disables ANSI colors in the console format of
+// NewConsole. If not provided, colors are enabled if the destination is a
+// terminal and the NO_COLOR environment variable is not set.
+func WithColor(enabled bool) Option {
+	return func(o *options) {
+		o.color = &enabled
+	}
+}
+
+// WithMultiline renders records in the console format of NewConsole on
+// multiple lines: the message on the first line, followed by each key-value
+// pair on its own indented line. Maps, structs and slices are rendered as
+// indented JSON and errors are expanded into their chain of wrapped errors.
+// It is intended for development, where long lines holding nested values are
+// unreadable.
+func WithMultiline() Option {
+	return func(o *options) {
+		o.multiline = true
+	}
+}
+
+// NewConsole returns a new telemetry.Logger implementation writing a human
+// friendly format to w, intended for local development. Each record is
+// rendered on a single line holding the time, the level and the message,
+// padded to align the key-value pairs following it. With colors enabled, see
+// WithColor, levels are colored by severity and key-value pairs are dimmed.
+// See WithMultiline for rendering nested values.
+func NewConsole(w io.Writer, opts ...Option) *Logger {
+	o := newOptions(opts)
+	if isNilWriter(w) {
+		o.reportError(ErrNilWriter)
+		w = os.Stderr
+	}
+	color := isTerminal(w) && os.Getenv("NO_COLOR") == ""
+	if o.color != nil {
+		color = *o.color
+	}
+	return newLogger(&encoderSink{
+		w:   o.measure(w),
+		enc: &consoleEncoder{color: color, multiline: o.multiline, location: o.timeLocation},
+	}, o)
+}
+
+// isTerminal returns true if w is a character device, such as a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// consoleEncoder renders records in the console format.
+type consoleEncoder struct {
+	color     bool
+	multiline bool
+	location  *time.Location
+}
+
+// Encode implements Encoder.
+func (c *consoleEncoder) Encode(w io.Writer, e *Entry) error {
+	var b bytes.Buffer
+	b.WriteString(e.Time.In(c.location).Format("15:04:05.000"))
+	b.WriteByte(' ')
+	lvl := fmt.Sprintf("%-5s", strings.ToUpper(recordLevelString(e.Level)))
+	c.paint(&b, consoleLevelColor(e.Level), lvl)
+	b.WriteByte(' ')
+	b.WriteString(e.Message)
+	if c.multiline {
+		c.encodeMultiline(&b, e)
+		_, err := w.Write(b.Bytes())
+		return err
+	}
+
+	var kvs bytes.Buffer
+	if e.Level == Error {
+		kvs.WriteString(" error=")
+		kvs.WriteString(consoleValue(e.Error))
+	}
+	for i := 0; i < len(e.KeyValues); i += 2 {
+		v := pairValue(e.KeyValues, i)
+		kvs.WriteByte(' ')
+		kvs.WriteString(fmt.Sprint(e.KeyValues[i]))
+		kvs.WriteByte('=')
+		kvs.WriteString(consoleValue(v))
+	}
+	if kvs.Len() > 0 {
+		if pad := consoleMessageWidth - len(e.Message); pad > 0 {
+			b.WriteString(strings.Repeat(" ", pad))
+		}
+		c.paint(&b, ansiDim, kvs.String())
+	}
+	b.WriteByte('\n')
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// encodeMultiline writes the key-value pairs of e to b, each on its own
+// indented line.
+func (c *consoleEncoder) encodeMultiline(b *bytes.Buffer, e *Entry) {
+	b.WriteByte('\n')
+	if e.Level == Error {
+		c.writeField(b, "error", consoleMultilineValue(e.Error))
+	}
+	for i := 0; i < len(e.KeyValues); i += 2 {
+		v := pairValue(e.KeyValues, i)
+		c.writeField(b, fmt.Sprint(e.KeyValues[i]), consoleMultilineValue(v))
+	}
+}
+
+// writeField writes an indented key-value pair line to b. Continuation lines
+// of the value are indented further.
+func (c *consoleEncoder) writeField(b *bytes.Buffer, key, value string) {
+	b.WriteString(consoleIndent)
+	c.paint(b, ansiDim, key+":")
+	b.WriteByte(' ')
+	b.WriteString(strings.ReplaceAll(value, "\n", "\n"+consoleIndent+consoleIndent))
+	b.WriteByte('\n')
+}
+
+// consoleIndent holds the indentation of key-value pairs in multi-line mode.
+const consoleIndent = "    "
+
+// consoleMultilineValue renders v for the multi-line console format.
+func consoleMultilineValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return t
+	case error:
+		return expandError(t)
+	case fmt.Stringer:
+		return t.String()
+	}
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array:
+		if b, err := json.MarshalIndent(v, "", "  "); err == nil {
+			return string(b)
+		}
+		return fmt.Sprintf("%+v", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// expandError renders err followed by the errors it wraps, each on its own
+// line.
+func expandError(err error) string {
+	var b strings.Builder
+	b.WriteString(err.Error())
+	for wrapped := errors.Unwrap(err); wrapped != nil; wrapped = errors.Unwrap(wrapped) {
+		b.WriteString("\ncaused by: ")
+		b.WriteString(wrapped.Error())
+	}
+	return b.String()
+}
+
+// paint writes s to b, wrapped in the ANSI escape sequence if colors are
+// enabled.
+func (c *consoleEncoder) paint(b *bytes.Buffer, seq, s string) {
+	if !c.color {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString(seq)
+	b.WriteString(s)
+	b.WriteString(ansiReset)
+}
+
+// consoleLevelColor returns the ANSI color of lvl.
+func consoleLevelColor(lvl Level) string {
+	switch {
+	case lvl == Error:
+		return ansiRed
+	case lvl == Warn:
+		return ansiYellow
+	case lvl == Info:
+		return ansiGreen
+	case lvl == Debug:
+		return ansiBlue
+	default:
+		return ansiMagenta
+	}
+}
+
+// consoleValue renders v, quoting it if needed to keep the line unambiguous.
+func consoleValue(v interface{}) string {
+	var s string
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		s = t
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
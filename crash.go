@@ -0,0 +1,133 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.23
+// +build go1.23
+
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// CrashMonitorEnv holds the name of the environment variable marking the
+// crash monitor process started by MonitorCrashes.
+const CrashMonitorEnv = "LOG_CRASH_MONITOR"
+
+// MonitorCrashes captures fatal runtime crashes, such as unrecovered panics
+// and fatal errors, and emits them as structured Error records through l. As
+// a crashing process can't log its own death, the executable is started a
+// second time, with the same arguments, as monitor process, so it configures
+// l the same way. The monitor process receives the crash output through
+// debug.SetCrashOutput. MonitorCrashes must be called early in main, after l
+// is configured but before any other work is done, as in the monitor process
+// it does not return:
+//
+//	func main() {
+//		l := logger.NewSyncJSON(os.Stderr)
+//		if err := logger.MonitorCrashes(l); err != nil {
+//			l.Error("crash monitoring unavailable", err)
+//		}
+//		...
+//	}
+func MonitorCrashes(l *Logger) error {
+	if os.Getenv(CrashMonitorEnv) != "" {
+		emitCrash(l, os.Stdin)
+		os.Exit(0)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), CrashMonitorEnv+"=1")
+	cmd.Stdin = r
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Start()
+	_ = r.Close()
+	if err != nil {
+		return err
+	}
+	return debug.SetCrashOutput(w, debug.CrashOptions{})
+}
+
+// emitCrash reads the crash output of the monitored process from r and emits
+// it as Error record. If the monitored process exited without crashing,
+// nothing is emitted.
+func emitCrash(l *Logger, r io.Reader) {
+	header, goroutine, stack := parseCrash(r)
+	if header == "" {
+		return
+	}
+	l.Error("process crashed", errors.New(header), "goroutine", goroutine, "stack", stack)
+	_ = Sync()
+}
+
+// parseCrash parses Go crash output into its header, e.g. "panic: boom", and
+// the id and stack of the first reported goroutine.
+func parseCrash(r io.Reader) (header string, goroutine int, stack Stack) {
+	var (
+		sc       = bufio.NewScanner(r)
+		inTrace  bool
+		done     bool
+		function string
+	)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case done:
+		case header == "":
+			header = strings.TrimSpace(line)
+		case !inTrace && strings.HasPrefix(line, "goroutine "):
+			fields := strings.Fields(line)
+			goroutine, _ = strconv.Atoi(fields[1])
+			inTrace = true
+		case inTrace && line == "":
+			done = true
+		case inTrace && strings.HasPrefix(line, "\t"):
+			loc := strings.TrimSpace(line)
+			if i := strings.LastIndex(loc, " +0x"); i > 0 {
+				loc = loc[:i]
+			}
+			f := Frame{Func: function, File: loc}
+			if i := strings.LastIndexByte(loc, ':'); i > 0 {
+				f.File = loc[:i]
+				f.Line, _ = strconv.Atoi(loc[i+1:])
+			}
+			stack = append(stack, f)
+		case inTrace:
+			function = line
+			if i := strings.LastIndexByte(function, '('); i > 0 {
+				function = function[:i]
+			}
+		}
+	}
+	return header, goroutine, stack
+}
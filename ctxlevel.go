@@ -0,0 +1,57 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ctxLevelKey is the Context key of the Context scoped log level.
+type ctxLevelKey struct{}
+
+// ctxLevelsUsed is set once ContextWithLevel has been called, avoiding the
+// Context lookup on each record for applications not using the feature.
+var ctxLevelsUsed int32
+
+// ContextWithLevel returns a Context holding a log level which takes
+// precedence over the configured level of Loggers with that Context attached.
+// This enables per-request verbose, or quiet, logging without changing the
+// process-wide setting.
+func ContextWithLevel(ctx context.Context, lvl Level) context.Context {
+	atomic.StoreInt32(&ctxLevelsUsed, 1)
+	return context.WithValue(ctx, ctxLevelKey{}, lvl)
+}
+
+// LevelFromContext returns the log level stored in the provided Context by
+// ContextWithLevel, if any.
+func LevelFromContext(ctx context.Context) (Level, bool) {
+	if ctx == nil {
+		return None, false
+	}
+	lvl, ok := ctx.Value(ctxLevelKey{}).(Level)
+	return lvl, ok
+}
+
+// threshold returns the effective log level of l, consulting the Context
+// scoped level before the configured level.
+func (l *Logger) threshold() Level {
+	if atomic.LoadInt32(&ctxLevelsUsed) != 0 {
+		if lvl, ok := LevelFromContext(l.ctx); ok {
+			return lvl
+		}
+	}
+	return Level(atomic.LoadInt32(l.lvl))
+}
@@ -0,0 +1,92 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"os"
+	"sync/atomic"
+
+	"github.com/tetratelabs/telemetry"
+)
+
+// defaultLogger holds the package's default Logger.
+var defaultLogger atomic.Value
+
+func init() {
+	defaultLogger.Store(NewSyncLogfmt(os.Stderr))
+}
+
+// Default returns the package's default Logger. Unless replaced through
+// SetDefault, it writes logfmt to stderr.
+func Default() *Logger {
+	return defaultLogger.Load().(*Logger)
+}
+
+// SetDefault atomically replaces the package's default Logger.
+func SetDefault(l *Logger) {
+	if l == nil {
+		return
+	}
+	defaultLogger.Store(l)
+}
+
+// The package level logging functions delegate to the default Logger. As the
+// Trace, Debug, Info, Warn and Error identifiers are taken by the Level
+// constants, they carry a Log prefix.
+
+// LogDebug logs a Debug record with key-value pairs using the default Logger.
+func LogDebug(msg string, keyValues ...interface{}) {
+	l := Default()
+	l.log(l.debugLevel(), msg, nil, keyValues)
+}
+
+// LogTrace logs a Trace record with key-value pairs using the default Logger.
+func LogTrace(msg string, keyValues ...interface{}) {
+	Default().log(Trace, msg, nil, keyValues)
+}
+
+// LogInfo logs an Info record with key-value pairs using the default Logger.
+func LogInfo(msg string, keyValues ...interface{}) {
+	l := Default()
+	if l.metric != nil {
+		l.metric.RecordContext(l.ctx, 1)
+	}
+	l.log(l.infoLevel(), msg, nil, keyValues)
+}
+
+// LogWarn logs a Warn record with key-value pairs using the default Logger.
+func LogWarn(msg string, keyValues ...interface{}) {
+	l := Default()
+	if l.metric != nil {
+		l.metric.RecordContext(l.ctx, 1)
+	}
+	l.log(Warn, msg, nil, keyValues)
+}
+
+// LogError logs an Error record with key-value pairs using the default Logger.
+func LogError(msg string, err error, keyValues ...interface{}) {
+	l := Default()
+	if l.metric != nil {
+		l.metric.RecordContext(l.ctx, 1)
+	}
+	l.log(Error, msg, err, keyValues)
+}
+
+// With returns a Logger derived from the default Logger with the provided
+// key-value pairs attached. The returned Logger is not affected by later
+// calls to SetDefault.
+func With(keyValues ...interface{}) telemetry.Logger {
+	return Default().With(keyValues...)
+}
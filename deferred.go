@@ -0,0 +1,147 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/tetratelabs/multierror"
+)
+
+// Deferred binds the backend of a Logger created by NewDeferred once logging
+// has been configured.
+type Deferred struct {
+	mtx     sync.Mutex
+	logger  *Logger
+	size    int
+	buffer  []*Entry
+	dropped int
+	target  *Logger
+	remove  func()
+}
+
+// NewDeferred returns a new telemetry.Logger implementation whose backend is
+// bound later through SetTarget of the returned Deferred. This allows
+// libraries to log during initialization, before main has configured
+// logging. Until bound, up to size records at any level are buffered; later
+// records are dropped. The options provided apply to the content of records,
+// e.g. redaction and Context handling, while the output is handled by the
+// target.
+func NewDeferred(size int, opts ...Option) (*Logger, *Deferred) {
+	o := newOptions(opts)
+	o.level = Trace
+	d := &Deferred{size: size}
+	d.logger = newLogger(&deferredSink{deferred: d}, o)
+	return d.logger, d
+}
+
+// SetTarget binds the deferred Logger to target. Buffered records enabled by
+// the level of target are emitted to it, followed by a Warn record holding
+// the number of dropped records, if any. From then on, records are emitted to
+// target directly and the level of the deferred Logger follows the level of
+// target. SetTarget can be called again to rebind to another target.
+func (d *Deferred) SetTarget(target *Logger) error {
+	if target == nil {
+		return errors.New("nil target Logger")
+	}
+	d.mtx.Lock()
+	if d.remove != nil {
+		d.remove()
+	}
+	d.target = target
+	d.remove = target.OnLevelChange(func(_, lvl Level) {
+		d.logger.storeLevel(lvl)
+	})
+	mErr := d.replay(target)
+	d.mtx.Unlock()
+
+	// level hooks are invoked outside of the lock, as they might log
+	d.logger.storeLevel(target.Level())
+	return mErr
+}
+
+// replay emits the buffered records to target. It must be called with d.mtx
+// held.
+func (d *Deferred) replay(target *Logger) error {
+	var mErr error
+	buffer := make([]*Entry, 0, len(d.buffer))
+	for _, e := range d.buffer {
+		if target.Enabled(e.Level) {
+			buffer = append(buffer, e)
+		}
+	}
+	if len(buffer) > 0 {
+		if err := target.sink.emit(buffer...); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	if d.dropped > 0 && target.Enabled(Warn) {
+		e := d.logger.entry(Warn, "deferred records dropped", nil,
+			[]interface{}{"dropped", d.dropped})
+		if err := target.sink.emit(e); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	d.buffer, d.dropped = nil, 0
+	return mErr
+}
+
+// deferredSink buffers entries until its Deferred is bound to a target.
+type deferredSink struct {
+	deferred *Deferred
+}
+
+func (s *deferredSink) emit(entries ...*Entry) error {
+	d := s.deferred
+	d.mtx.Lock()
+	target := d.target
+	if target == nil {
+		for _, e := range entries {
+			if len(d.buffer) < d.size {
+				d.buffer = append(d.buffer, e)
+			} else {
+				d.dropped++
+			}
+		}
+	}
+	d.mtx.Unlock()
+
+	if target == nil {
+		return nil
+	}
+	// the level of the deferred Logger trails the target while binding
+	enabled := make([]*Entry, 0, len(entries))
+	for _, e := range entries {
+		if target.Enabled(e.Level) {
+			enabled = append(enabled, e)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil
+	}
+	return target.sink.emit(enabled...)
+}
+
+// sink returns the sink of the target, or nil if not bound.
+func (s *deferredSink) sink() sink {
+	s.deferred.mtx.Lock()
+	defer s.deferred.mtx.Unlock()
+
+	if s.deferred.target == nil {
+		return nil
+	}
+	return s.deferred.target.sink
+}
@@ -0,0 +1,117 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/log"
+)
+
+// DryRun discards fully encoded records while gathering statistics about
+// them. It allows estimating the cost of logging, e.g. before enabling a
+// verbose feature in production.
+type DryRun struct {
+	// 64-bit fields are accessed atomically and must remain first for
+	// alignment on 32-bit platforms.
+	records int64
+	bytes   int64
+	fields  int64
+
+	clock Clock
+	start time.Time
+}
+
+// DryRunStats holds the statistics gathered by a DryRun.
+type DryRunStats struct {
+	// Records holds the number of encoded records.
+	Records int64
+	// Bytes holds the number of encoded bytes.
+	Bytes int64
+	// Fields holds the number of encoded key-value pairs, excluding the
+	// built-in fields.
+	Fields int64
+	// Elapsed holds the duration since the DryRun started.
+	Elapsed time.Duration
+}
+
+// BytesPerSecond returns the average number of encoded bytes per second.
+func (s DryRunStats) BytesPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / s.Elapsed.Seconds()
+}
+
+// FieldsPerRecord returns the average number of key-value pairs per record.
+func (s DryRunStats) FieldsPerRecord() float64 {
+	if s.Records == 0 {
+		return 0
+	}
+	return float64(s.Fields) / float64(s.Records)
+}
+
+// String implements fmt.Stringer.
+func (s DryRunStats) String() string {
+	return fmt.Sprintf("%d records, %d bytes in %s (%.1f bytes/s, %.1f fields/record)",
+		s.Records, s.Bytes, s.Elapsed, s.BytesPerSecond(), s.FieldsPerRecord())
+}
+
+// NewDryRun returns a new telemetry.Logger implementation which fully encodes
+// records with the provided Encoder, or Go kit's logfmt format if enc is nil,
+// but discards the output. Statistics are gathered in the returned DryRun.
+func NewDryRun(enc Encoder, opts ...Option) (*Logger, *DryRun) {
+	o := newOptions(opts)
+	d := &DryRun{clock: o.clock, start: o.clock.Now()}
+
+	var s sink = newKitSink(newSyncLogger(log.NewLogfmtLogger(d)), o)
+	if enc != nil {
+		s = &encoderSink{w: d, enc: enc}
+	}
+
+	return newLogger(dryRunSink{dryRun: d, sink: s}, o), d
+}
+
+// Write implements io.Writer.
+func (d *DryRun) Write(p []byte) (int, error) {
+	atomic.AddInt64(&d.bytes, int64(len(p)))
+	return len(p), nil
+}
+
+// Stats returns the statistics gathered so far.
+func (d *DryRun) Stats() DryRunStats {
+	return DryRunStats{
+		Records: atomic.LoadInt64(&d.records),
+		Bytes:   atomic.LoadInt64(&d.bytes),
+		Fields:  atomic.LoadInt64(&d.fields),
+		Elapsed: d.clock.Now().Sub(d.start),
+	}
+}
+
+// dryRunSink counts the records and fields passing through to sink.
+type dryRunSink struct {
+	dryRun *DryRun
+	sink   sink
+}
+
+func (s dryRunSink) emit(entries ...*Entry) error {
+	for _, e := range entries {
+		atomic.AddInt64(&s.dryRun.records, 1)
+		atomic.AddInt64(&s.dryRun.fields, int64(len(e.KeyValues)/2))
+	}
+	return s.sink.emit(entries...)
+}
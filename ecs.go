@@ -0,0 +1,79 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ECSVersion holds the Elastic Common Schema version emitted by the Encoder
+// returned by NewECSEncoder.
+const ECSVersion = "8.11.0"
+
+// NewECSEncoder returns an Encoder emitting records as JSON objects using
+// Elastic Common Schema field names, allowing Filebeat and Elasticsearch
+// ingest pipelines to consume the output without further mutations. The
+// timestamp is emitted as @timestamp in UTC, the level as log.level, the
+// message as message and the error of Error records as error.message. A
+// stacktrace field, see WithStacktrace, is emitted as error.stack_trace. All
+// other key-value pairs are emitted as is. Use it with NewWithEncoder.
+func NewECSEncoder() Encoder {
+	return EncoderFunc(encodeECS)
+}
+
+func encodeECS(w io.Writer, e *Entry) error {
+	fields := make(map[string]interface{}, 5+len(e.KeyValues)/2)
+	for i := 0; i < len(e.KeyValues); i += 2 {
+		v := pairValue(e.KeyValues, i)
+		k := fmt.Sprint(e.KeyValues[i])
+		if k == "stacktrace" {
+			// ECS expects a flat string
+			k = "error.stack_trace"
+			if s, ok := v.(Stack); ok {
+				v = s.String()
+			}
+		}
+		fields[k] = jsonValue(v)
+	}
+	fields["@timestamp"] = e.Time.UTC().Format(time.RFC3339Nano)
+	fields["log.level"] = recordLevelString(e.Level)
+	fields["message"] = e.Message
+	fields["ecs.version"] = ECSVersion
+	if e.Level == Error && e.Error != nil {
+		fields["error.message"] = e.Error.Error()
+	}
+	return json.NewEncoder(w).Encode(fields)
+}
+
+// jsonValue returns v in a form which can be safely marshaled to JSON.
+func jsonValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case error:
+		return t.Error()
+	case json.Marshaler:
+		return t
+	case fmt.Stringer:
+		return t.String()
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return v
+}
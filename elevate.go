@@ -0,0 +1,134 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ElevateLevel raises the minimum log output level of the named scope to lvl
+// for the provided duration, after which the previous level is restored. The
+// level is also restored when the provided Context is done or when the
+// returned revert function is called, whichever comes first. This prevents
+// incident responders from forgetting to turn debug logging back off.
+// If the scope is already at or above lvl, its level is left untouched. The
+// previous level, and whether the scope inherited it from a parent scope, is
+// only restored if the level of the scope was not changed in the meantime.
+func (s *ScopeManager) ElevateLevel(ctx context.Context, name string, lvl Level, d time.Duration) (revert func(), err error) {
+	name = scopeName(name)
+	s.mtx.Lock()
+	sc, has := s.registry[name]
+	var explicit bool
+	if has {
+		explicit = sc.explicit
+	}
+	s.mtx.Unlock()
+	if !has {
+		return nil, fmt.Errorf("scope %q not found", name)
+	}
+	prev := sc.logger.Level()
+	if prev >= lvl {
+		return func() {}, nil
+	}
+	if err = s.SetScopeOutputLevel(name, lvl); err != nil {
+		return nil, err
+	}
+	elevated := sc.logger.Level()
+
+	return revertAfter(ctx, d, func() {
+		s.restoreScopeLevel(sc, elevated, prev, explicit)
+	}), nil
+}
+
+// restoreScopeLevel reverts an elevation of sc, and of the scopes inheriting
+// its level, from elevated to prev, unless the level was changed since. A
+// scope which inherited its level before the elevation inherits again,
+// taking the current level of its parent.
+func (s *ScopeManager) restoreScopeLevel(sc *scopedLogger, elevated, prev Level, explicit bool) {
+	s.mtx.Lock()
+	if s.registry[sc.name] != sc || sc.logger.Level() != elevated {
+		s.mtx.Unlock()
+		return
+	}
+	if !explicit {
+		prev = s.logger.Level()
+		if parent := s.parent(sc.name); parent != nil {
+			prev = parent.logger.Level()
+		}
+	}
+	sc.explicit = explicit
+	children := s.inheriting(sc.name)
+	s.mtx.Unlock()
+
+	for _, l := range append(children, sc) {
+		if atomic.CompareAndSwapInt32(l.logger.lvl, int32(elevated), int32(prev)) && prev != elevated {
+			l.logger.hooks.notify("", elevated, prev)
+		}
+	}
+}
+
+// SetLevelFor sets the log level of l to lvl for the provided duration, after
+// which the previous level is restored. The level is also restored when the
+// returned cancel function is called, whichever comes first. This prevents
+// temporary debug windows from being left on by accident. The previous level
+// is restored as is, regardless of the ClampPolicy, and only if the level was
+// not changed in the meantime.
+func (l *Logger) SetLevelFor(lvl Level, d time.Duration) (cancel func(), err error) {
+	prev := l.Level()
+	applied, err := l.ApplyLevel(lvl)
+	if err != nil {
+		return nil, err
+	}
+
+	return revertAfter(context.Background(), d, func() {
+		// prev was accepted before, so it is restored verbatim
+		if prev != applied && atomic.CompareAndSwapInt32(l.lvl, int32(applied), int32(prev)) {
+			l.hooks.notify("", applied, prev)
+		}
+	}), nil
+}
+
+// revertAfter calls restore once the provided duration has passed, the
+// provided Context is done or the returned revert function is called,
+// whichever comes first. Restore is called at most once.
+func revertAfter(ctx context.Context, d time.Duration, restore func()) (revert func()) {
+	var (
+		once sync.Once
+		done = make(chan struct{})
+	)
+	revert = func() {
+		once.Do(func() {
+			close(done)
+			restore()
+		})
+	}
+	go func() {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+		revert()
+	}()
+
+	return revert
+}
@@ -0,0 +1,138 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tetratelabs/telemetry-gokit-log/logtest"
+)
+
+func TestSetLevelForRestoresVerbatim(t *testing.T) {
+	l := NewSyncLogfmt(logtest.NewWriter(), WithClampPolicy(ClampStrict))
+	l.SetRawLevel(7)
+
+	cancel, err := l.SetLevelFor(Debug, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lvl := l.Level(); lvl != Debug {
+		t.Fatalf("expected level %d, got %d", Debug, lvl)
+	}
+	cancel()
+	if lvl := l.Level(); lvl != 7 {
+		t.Errorf("expected level 7 to be restored, got %d", lvl)
+	}
+}
+
+func TestSetLevelForKeepsLaterChanges(t *testing.T) {
+	l := NewSyncLogfmt(logtest.NewWriter())
+
+	cancel, err := l.SetLevelFor(Debug, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetLevel(Error)
+	cancel()
+	if lvl := l.Level(); lvl != Error {
+		t.Errorf("expected level %d to be kept, got %d", Error, lvl)
+	}
+}
+
+func TestSetLevelForExpires(t *testing.T) {
+	l := NewSyncLogfmt(logtest.NewWriter())
+
+	changed := make(chan Level, 2)
+	l.OnLevelChange(func(_, new Level) { changed <- new })
+	if _, err := l.SetLevelFor(Debug, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []Level{Debug, Info} {
+		select {
+		case lvl := <-changed:
+			if lvl != want {
+				t.Fatalf("expected level %d, got %d", want, lvl)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("level %d not applied", want)
+		}
+	}
+}
+
+func TestElevateLevelRestoresInheritance(t *testing.T) {
+	s := NewScopeManager(NewSyncLogfmt(logtest.NewWriter()))
+	server := s.Register("server", "")
+	http := s.Register("server.http", "")
+
+	revert, err := s.ElevateLevel(context.Background(), "server", Debug, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.Level() != Debug || http.Level() != Debug {
+		t.Fatalf("expected elevated levels, got %d and %d", server.Level(), http.Level())
+	}
+	revert()
+	if server.Level() != Info || http.Level() != Info {
+		t.Fatalf("expected restored levels, got %d and %d", server.Level(), http.Level())
+	}
+
+	// server.http inherits the level of server again
+	if revert, err = s.ElevateLevel(context.Background(), "server.http", Debug, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	revert()
+	if err = s.SetScopeOutputLevel("server", Error); err != nil {
+		t.Fatal(err)
+	}
+	if lvl := http.Level(); lvl != Error {
+		t.Errorf("expected level %d to cascade, got %d", Error, lvl)
+	}
+}
+
+func TestElevateLevelRestoresExplicitLevel(t *testing.T) {
+	s := NewScopeManager(NewSyncLogfmt(logtest.NewWriter()))
+	server := s.Register("server", "")
+	if err := s.SetScopeOutputLevel("server", Warn); err != nil {
+		t.Fatal(err)
+	}
+
+	revert, err := s.ElevateLevel(context.Background(), "server", Debug, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revert()
+	if lvl := server.Level(); lvl != Warn {
+		t.Errorf("expected level %d, got %d", Warn, lvl)
+	}
+}
+
+func TestElevateLevelKeepsLaterChanges(t *testing.T) {
+	s := NewScopeManager(NewSyncLogfmt(logtest.NewWriter()))
+	server := s.Register("server", "")
+
+	revert, err := s.ElevateLevel(context.Background(), "server", Debug, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.SetScopeOutputLevel("server", Error); err != nil {
+		t.Fatal(err)
+	}
+	revert()
+	if lvl := server.Level(); lvl != Error {
+		t.Errorf("expected level %d to be kept, got %d", Error, lvl)
+	}
+}
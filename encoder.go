@@ -0,0 +1,33 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "io"
+
+// Encoder encodes entries into a custom output format. It allows for
+// implementing formats without the need to implement a Go kit log.Logger and
+// re-parse interleaved key-value pairs.
+type Encoder interface {
+	// Encode writes the provided Entry to w. The Entry must not be retained.
+	Encode(w io.Writer, e *Entry) error
+}
+
+// EncoderFunc is an adapter to allow the use of ordinary functions as Encoder.
+type EncoderFunc func(w io.Writer, e *Entry) error
+
+// Encode implements Encoder.
+func (f EncoderFunc) Encode(w io.Writer, e *Entry) error {
+	return f(w, e)
+}
@@ -0,0 +1,34 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "time"
+
+// Entry holds a single log record.
+type Entry struct {
+	// Level holds the severity of the record.
+	Level Level
+	// Time holds the moment the record was created.
+	Time time.Time
+	// Message holds the log message.
+	Message string
+	// Error holds the error of the record. It is only emitted for records at
+	// the Error level.
+	Error error
+	// KeyValues holds the key-value pairs of the record. When handed to an
+	// Encoder, it holds the pairs found in Context, followed by the pairs added
+	// through With and the pairs provided at the call site.
+	KeyValues []interface{}
+}
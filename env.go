@@ -0,0 +1,52 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-kit/log"
+)
+
+// LevelEnv holds the name of the environment variable consulted by NewFromEnv
+// if no variable name is provided.
+const LevelEnv = "LOG_LEVEL"
+
+// NewFromEnv returns a new telemetry.Logger implementation based on Go kit log
+// with its initial level read from the named environment variable, allowing
+// deployments to control verbosity without code changes. If name is empty,
+// LevelEnv is used. The value is parsed with ParseLevel. If the variable is
+// unset or empty the level defaults to Info. Invalid values are reported to
+// the ErrorHandler, after which the default is used as well.
+func NewFromEnv(logger log.Logger, name string, opts ...Option) *Logger {
+	o := newOptions(opts)
+	if name == "" {
+		name = LevelEnv
+	}
+	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+		lvl, err := ParseLevel(value)
+		if err == nil {
+			lvl, err = o.clampPolicy.clamp(lvl)
+		}
+		if err != nil {
+			o.reportError(fmt.Errorf("environment variable %s: %w", name, err))
+		} else {
+			o.level = lvl
+		}
+	}
+	return newKitLogger(logger, o)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+// ErrorFormatter renders an error value into the string representation to
+// log. It allows for backend specific error shapes such as including status
+// codes or stripping wrapping prefixes.
+type ErrorFormatter func(err error) string
+
+// formattedError holds an error together with its formatted representation.
+// The original error remains accessible through errors.Unwrap.
+type formattedError struct {
+	err error
+	msg string
+}
+
+func (e formattedError) Error() string {
+	return e.msg
+}
+
+func (e formattedError) Unwrap() error {
+	return e.err
+}
+
+// formatErrors applies the configured ErrorFormatter to the record error and
+// all error values found in the record's key-value pairs.
+func (o *options) formatErrors(e *Entry) {
+	if o.errorFormatter == nil {
+		return
+	}
+	if e.Error != nil {
+		e.Error = formattedError{err: e.Error, msg: o.errorFormatter(e.Error)}
+	}
+	for i := 1; i < len(e.KeyValues); i += 2 {
+		if err, ok := e.KeyValues[i].(error); ok && err != nil {
+			e.KeyValues[i] = formattedError{err: err, msg: o.errorFormatter(err)}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxEscalationMessages holds the maximum number of distinct messages counted
+// for escalation at a time.
+const maxEscalationMessages = 10000
+
+// WithEscalation escalates recurring Info and Warn records to Error, so slowly
+// degrading conditions eventually trip alerting. Each time a message has been
+// logged n times within the provided window, that occurrence is emitted as
+// Error record, holding escalated_from and occurrences fields. Occurrences
+// suppressed by the level configuration are counted as well. The Clock of the
+// Logger is used for windows. Expired windows are swept at most once per
+// window and at most 10000 distinct messages are counted at a time, so high
+// cardinality messages, e.g. from Infof, can't exhaust memory. If n or window
+// is not positive, escalation is disabled and an error is reported to the
+// ErrorHandler.
+func WithEscalation(n int, window time.Duration) Option {
+	return func(o *options) {
+		if n <= 0 || window <= 0 {
+			o.escalation = nil
+			o.invalid = append(o.invalid,
+				fmt.Errorf("invalid escalation of %d occurrences within %s, escalation disabled", n, window))
+			return
+		}
+		o.escalation = &escalation{
+			threshold: int64(n),
+			window:    int64(window),
+		}
+	}
+}
+
+// escalation counts the occurrences of Info and Warn messages.
+type escalation struct {
+	threshold int64
+	window    int64
+
+	// counts maps messages to their *occurrences.
+	counts sync.Map
+	// size holds the number of entries of counts.
+	size int64
+	// lastSweep holds the time, in Unix nanoseconds, of the last sweep of
+	// expired windows.
+	lastSweep int64
+}
+
+// occurrences holds the count of a message within its current window.
+type occurrences struct {
+	// start holds the start of the window in Unix nanoseconds.
+	start int64
+	n     int64
+}
+
+// escalate counts an occurrence of msg and reports whether it is to be
+// escalated.
+func (e *escalation) escalate(now time.Time, msg string) bool {
+	ts := now.UnixNano()
+	e.sweep(ts)
+
+	v, ok := e.counts.Load(msg)
+	if !ok {
+		if atomic.LoadInt64(&e.size) >= maxEscalationMessages {
+			return false
+		}
+		var loaded bool
+		if v, loaded = e.counts.LoadOrStore(msg, &occurrences{start: ts}); !loaded {
+			atomic.AddInt64(&e.size, 1)
+		}
+	}
+	c := v.(*occurrences)
+	if start := atomic.LoadInt64(&c.start); ts-start >= e.window &&
+		atomic.CompareAndSwapInt64(&c.start, start, ts) {
+		atomic.StoreInt64(&c.n, 0)
+	}
+	return atomic.AddInt64(&c.n, 1)%e.threshold == 0
+}
+
+// sweep drops the messages of expired windows, at most once per window.
+func (e *escalation) sweep(ts int64) {
+	last := atomic.LoadInt64(&e.lastSweep)
+	if ts-last < e.window || !atomic.CompareAndSwapInt64(&e.lastSweep, last, ts) {
+		return
+	}
+	e.counts.Range(func(k, v interface{}) bool {
+		if ts-atomic.LoadInt64(&v.(*occurrences).start) >= e.window {
+			if _, ok := e.counts.LoadAndDelete(k); ok {
+				atomic.AddInt64(&e.size, -1)
+			}
+		}
+		return true
+	})
+}
+
+// escalated returns the level and key-value pairs of a record at lvl after
+// applying the escalation policy.
+func (l *Logger) escalated(lvl Level, msg string, keyValues []interface{}) (Level, []interface{}) {
+	if l.opts.escalation == nil || (lvl != Info && lvl != Warn) {
+		return lvl, keyValues
+	}
+	if !l.opts.escalation.escalate(l.opts.clock.Now(), msg) {
+		return lvl, keyValues
+	}
+	args := make([]interface{}, 0, len(keyValues)+4)
+	args = append(args, keyValues...)
+	args = append(args,
+		"escalated_from", levelString(lvl),
+		"occurrences", l.opts.escalation.threshold,
+	)
+	return Error, args
+}
@@ -0,0 +1,39 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+// WithExitFunc sets the function called by Fatal after the record has been
+// emitted and all registered Flushers have been drained. If not provided
+// os.Exit is used. Overriding the exit function is mostly useful in tests.
+func WithExitFunc(exit func(code int)) Option {
+	return func(o *options) {
+		if exit != nil {
+			o.exit = exit
+		}
+	}
+}
+
+// Fatal logs an Error record with key-value pairs, drains all registered
+// Flushers and then terminates the program through the configured exit
+// function with status code 1. Use this only from main packages where the
+// application can't continue; library code should return errors instead.
+func (l *Logger) Fatal(msg string, err error, keyValues ...interface{}) {
+	if l.metric != nil {
+		l.metric.RecordContext(l.ctx, 1)
+	}
+	l.log(Error, msg, err, keyValues)
+	_ = Sync()
+	l.opts.exit(1)
+}
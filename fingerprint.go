@@ -0,0 +1,48 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"strconv"
+)
+
+// WithErrorFingerprint adds an error.fingerprint field to Error records
+// holding an error. The fingerprint is a stable hash of the message, the type
+// of the error and the function calling the logging method, enabling dedup
+// and grouping in log backends lacking Sentry-style grouping. As file and line
+// are not part of the hash, fingerprints are stable across edits elsewhere in
+// the code.
+func WithErrorFingerprint() Option {
+	return func(o *options) {
+		o.fingerprint = true
+	}
+}
+
+// fingerprint returns the fingerprint of an error record. The argument skip is
+// the number of stack frames to skip to arrive at the calling site, with 0
+// identifying the caller of fingerprint.
+func fingerprint(skip int, msg string, err error) string {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%s\x00%T\x00", msg, err)
+	if pc, _, _, ok := runtime.Caller(skip + 1); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			_, _ = h.Write([]byte(fn.Name()))
+		}
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/tetratelabs/multierror"
+)
+
+// Flusher is implemented by sinks and writers which buffer records, such as
+// buffered, asynchronous and network destinations.
+type Flusher interface {
+	// Flush drains all buffered records. It should return early with an
+	// error if the provided Context is done.
+	Flush(ctx context.Context) error
+}
+
+// FlusherFunc is an adapter to allow the use of ordinary functions as Flusher.
+type FlusherFunc func(ctx context.Context) error
+
+// Flush implements Flusher.
+func (f FlusherFunc) Flush(ctx context.Context) error {
+	return f(ctx)
+}
+
+var flushers = struct {
+	mtx  sync.Mutex
+	seq  uint64
+	list map[uint64]Flusher
+}{
+	list: make(map[uint64]Flusher),
+}
+
+// RegisterFlusher adds the provided Flusher to the package level registry
+// drained by FlushAll and Sync. The returned function removes the Flusher
+// from the registry.
+func RegisterFlusher(f Flusher) (deregister func()) {
+	flushers.mtx.Lock()
+	defer flushers.mtx.Unlock()
+
+	flushers.seq++
+	id := flushers.seq
+	flushers.list[id] = f
+
+	return func() {
+		flushers.mtx.Lock()
+		defer flushers.mtx.Unlock()
+
+		delete(flushers.list, id)
+	}
+}
+
+// FlushAll drains all registered Flushers in order of registration. It is
+// intended to be deferred in main() to guarantee buffered records are written
+// at shutdown. Flushers not yet invoked when the Context is done are skipped.
+func FlushAll(ctx context.Context) error {
+	flushers.mtx.Lock()
+	ids := make([]uint64, 0, len(flushers.list))
+	for id := range flushers.list {
+		ids = append(ids, id)
+	}
+	list := make([]Flusher, 0, len(ids))
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		list = append(list, flushers.list[id])
+	}
+	flushers.mtx.Unlock()
+
+	var mErr error
+	for _, f := range list {
+		if err := ctx.Err(); err != nil {
+			return multierror.Append(mErr, err)
+		}
+		if err := f.Flush(ctx); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr
+}
+
+// Sync drains all registered Flushers without a deadline.
+func Sync() error {
+	return FlushAll(context.Background())
+}
@@ -0,0 +1,104 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys of the Google Cloud Logging structured format with special meaning.
+const (
+	gcpTraceKey          = "logging.googleapis.com/trace"
+	gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+)
+
+// NewGCPEncoder returns an Encoder emitting records as JSON objects using the
+// Google Cloud Logging structured format, so records written to stdout in GKE
+// or Cloud Run are parsed with the correct severity. The level is emitted as
+// severity, the timestamp as time and the message as message.
+//
+// If traceKey is set, the value of the key-value pair holding that key is
+// emitted as logging.googleapis.com/trace in the form of
+// projects/<projectID>/traces/<value>, correlating records with Cloud Trace.
+// The caller field, see WithCaller, is emitted as
+// logging.googleapis.com/sourceLocation. All other key-value pairs are emitted
+// as is. Use it with NewWithEncoder.
+func NewGCPEncoder(projectID, traceKey string) Encoder {
+	return EncoderFunc(func(w io.Writer, e *Entry) error {
+		fields := make(map[string]interface{}, 4+len(e.KeyValues)/2)
+		for i := 0; i < len(e.KeyValues); i += 2 {
+			v := pairValue(e.KeyValues, i)
+			k := fmt.Sprint(e.KeyValues[i])
+			switch {
+			case k == traceKey && traceKey != "":
+				fields[gcpTraceKey] = "projects/" + projectID + "/traces/" + fmt.Sprint(v)
+			case k == "caller":
+				if loc, ok := gcpSourceLocation(v); ok {
+					fields[gcpSourceLocationKey] = loc
+					continue
+				}
+				fields[k] = jsonValue(v)
+			default:
+				fields[k] = jsonValue(v)
+			}
+		}
+		fields["severity"] = gcpSeverity(e.Level)
+		fields["time"] = e.Time.UTC().Format(time.RFC3339Nano)
+		fields["message"] = e.Message
+		if e.Level == Error && e.Error != nil {
+			fields["error"] = e.Error.Error()
+		}
+		return json.NewEncoder(w).Encode(fields)
+	})
+}
+
+// gcpSeverity returns the Cloud Logging severity of lvl.
+func gcpSeverity(lvl Level) string {
+	switch {
+	case lvl == Error:
+		return "ERROR"
+	case lvl == Warn:
+		return "WARNING"
+	case lvl == Info:
+		return "INFO"
+	case lvl > Info:
+		return "DEBUG"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// gcpSourceLocation returns the sourceLocation of a caller field value in the
+// form of file:line.
+func gcpSourceLocation(v interface{}) (map[string]interface{}, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, false
+	}
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return nil, false
+	}
+	// Cloud Logging expects the line as a string holding an int64
+	if _, err := strconv.Atoi(s[i+1:]); err != nil {
+		return nil, false
+	}
+	return map[string]interface{}{"file": s[:i], "line": s[i+1:]}, true
+}
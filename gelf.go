@@ -0,0 +1,232 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+// NewGELFEncoder returns an Encoder emitting records in the Graylog Extended
+// Log Format (GELF) version 1.1. The message is emitted as short_message, the
+// level as syslog severity and key-value pairs as custom fields prefixed with
+// an underscore. Characters not allowed in GELF field names are replaced with
+// underscores. If host is empty, the hostname of the system is used. Use it
+// with NewWithEncoder and a GELFWriter to ship records to Graylog directly.
+func NewGELFEncoder(host string) Encoder {
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	return EncoderFunc(func(w io.Writer, e *Entry) error {
+		fields := make(map[string]interface{}, 6+len(e.KeyValues)/2)
+		for i := 0; i < len(e.KeyValues); i += 2 {
+			v := pairValue(e.KeyValues, i)
+			k := gelfFieldName(fmt.Sprint(e.KeyValues[i]))
+			if k == "_id" {
+				// reserved by GELF
+				k = "__id"
+			}
+			fields[k] = jsonValue(v)
+		}
+		fields["version"] = "1.1"
+		fields["host"] = host
+		fields["short_message"] = e.Message
+		fields["timestamp"] = float64(e.Time.UnixNano()/int64(1e6)) / 1e3
+		fields["level"] = gelfLevel(e.Level)
+		if e.Level == Error && e.Error != nil {
+			fields["_error"] = e.Error.Error()
+		}
+		b, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	})
+}
+
+// gelfLevel returns the syslog severity of lvl.
+func gelfLevel(lvl Level) int {
+	switch {
+	case lvl == Error:
+		return 3
+	case lvl == Warn:
+		return 4
+	case lvl == Info:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// gelfFieldName returns the custom field name of key.
+func gelfFieldName(key string) string {
+	return "_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
+
+// GELF chunking limits.
+const (
+	// DefaultGELFChunkSize holds the default maximum size of UDP datagrams,
+	// fitting common network MTUs.
+	DefaultGELFChunkSize = 1420
+	gelfChunkHeaderSize  = 12
+	gelfMaxChunks        = 128
+)
+
+// ErrGELFMessageTooLarge is returned if a message exceeds the maximum number
+// of GELF chunks.
+var ErrGELFMessageTooLarge = errors.New("GELF message too large")
+
+// GELFWriter writes GELF messages to Graylog over UDP or TCP. Over UDP, each
+// call to Write is sent as a single message, split into GELF chunks if it
+// exceeds the chunk size. Over TCP, messages are delimited by a null byte and
+// the connection is reestablished on the next Write after a failure. It is
+// safe for concurrent use.
+type GELFWriter struct {
+	mtx       sync.Mutex
+	conn      net.Conn
+	chunkSize int
+	// dial is set for TCP connections, which are redialed after failures.
+	dial func() (net.Conn, error)
+}
+
+// NewGELFWriter returns a GELFWriter sending to the Graylog UDP input at addr,
+// e.g. graylog:12201. If chunkSize is not positive, DefaultGELFChunkSize is
+// used. A chunkSize not exceeding the 12 byte chunk header is rejected.
+func NewGELFWriter(addr string, chunkSize int) (*GELFWriter, error) {
+	switch {
+	case chunkSize <= 0:
+		chunkSize = DefaultGELFChunkSize
+	case chunkSize <= gelfChunkHeaderSize:
+		return nil, fmt.Errorf("GELF chunk size %d must exceed the %d byte chunk header",
+			chunkSize, gelfChunkHeaderSize)
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &GELFWriter{conn: conn, chunkSize: chunkSize}, nil
+}
+
+// NewGELFTCPWriter returns a GELFWriter sending to the Graylog TCP input at
+// addr. If tlsConfig is not nil, the connection uses TLS, with the client
+// certificates, root CAs and server name of tlsConfig, as required for mutual
+// TLS.
+func NewGELFTCPWriter(addr string, tlsConfig *tls.Config) (*GELFWriter, error) {
+	dial := func() (net.Conn, error) {
+		if tlsConfig != nil {
+			return tls.Dial("tcp", addr, tlsConfig)
+		}
+		return net.Dial("tcp", addr)
+	}
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	return &GELFWriter{conn: conn, dial: dial}, nil
+}
+
+// Write implements io.Writer.
+func (g *GELFWriter) Write(p []byte) (int, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.dial != nil {
+		return g.writeStream(p)
+	}
+	if len(p) <= g.chunkSize {
+		if _, err := g.conn.Write(p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	payload := g.chunkSize - gelfChunkHeaderSize
+	count := (len(p) + payload - 1) / payload
+	if count > gelfMaxChunks {
+		return 0, ErrGELFMessageTooLarge
+	}
+	chunk := make([]byte, gelfChunkHeaderSize, g.chunkSize)
+	chunk[0], chunk[1] = 0x1e, 0x0f
+	if _, err := rand.Read(chunk[2:10]); err != nil {
+		return 0, err
+	}
+	chunk[11] = byte(count)
+	for seq := 0; seq < count; seq++ {
+		end := (seq + 1) * payload
+		if end > len(p) {
+			end = len(p)
+		}
+		chunk[10] = byte(seq)
+		chunk = append(chunk[:gelfChunkHeaderSize], p[seq*payload:end]...)
+		if _, err := g.conn.Write(chunk); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// writeStream writes p as a null byte delimited message to the TCP
+// connection. It must be called with g.mtx held.
+func (g *GELFWriter) writeStream(p []byte) (int, error) {
+	if g.conn == nil {
+		conn, err := g.dial()
+		if err != nil {
+			return 0, err
+		}
+		g.conn = conn
+	}
+	msg := make([]byte, len(p)+1)
+	copy(msg, p)
+	if _, err := g.conn.Write(msg); err != nil {
+		_ = g.conn.Close()
+		g.conn = nil
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the underlying connection.
+func (g *GELFWriter) Close() error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.conn == nil {
+		return nil
+	}
+	err := g.conn.Close()
+	if g.dial != nil {
+		// prevent redialing after Close
+		g.dial = func() (net.Conn, error) { return nil, net.ErrClosed }
+		g.conn = nil
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// levelPayload is the JSON representation of the level used by LevelHandler.
+type levelPayload struct {
+	Level string `json:"level,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// LevelHandler returns an http.Handler allowing the log level of l to be
+// inspected and changed at runtime, e.g. from an admin endpoint:
+//
+//	GET  responds with the current level: {"level":"info"}
+//	PUT  changes the level using a JSON body: {"level":"debug"}
+//	POST is equivalent to PUT
+//
+// For PUT and POST, the level can also be provided as form value or query
+// parameter named "level". Levels are parsed with ParseLevel.
+func LevelHandler(l *Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			lvl, err := levelFromRequest(r)
+			if err == nil {
+				_, err = l.ApplyLevel(lvl)
+			}
+			if err != nil {
+				writeLevelPayload(w, http.StatusBadRequest, levelPayload{Error: err.Error()})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeLevelPayload(w, http.StatusMethodNotAllowed,
+				levelPayload{Error: fmt.Sprintf("method %s not allowed", r.Method)})
+			return
+		}
+		writeLevelPayload(w, http.StatusOK, levelPayload{Level: l.Level().String()})
+	})
+}
+
+// levelFromRequest returns the level provided in the request body, form or
+// query.
+func levelFromRequest(r *http.Request) (Level, error) {
+	var p levelPayload
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return None, fmt.Errorf("malformed request body: %w", err)
+		}
+	} else {
+		p.Level = r.FormValue("level")
+	}
+	if p.Level == "" {
+		return None, errors.New("missing level")
+	}
+	return ParseLevel(p.Level)
+}
+
+func writeLevelPayload(w http.ResponseWriter, code int, p levelPayload) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// WithHashedKeys replaces the values of the provided keys with a salted hash
+// of their textual representation. Records remain joinable on these keys,
+// e.g. user_id or email, without storing the raw identifiers. Values are
+// hashed using HMAC-SHA256 keyed with salt and emitted as the hex encoding of
+// the first 16 bytes. Keys are matched case-insensitively. Keys which are also
+// redacted are emitted as Redacted.
+func WithHashedKeys(salt []byte, keys ...string) Option {
+	return func(o *options) {
+		o.hashSalt = append([]byte(nil), salt...)
+		if o.hashed == nil {
+			o.hashed = make(map[string]struct{}, len(keys))
+		}
+		for _, k := range keys {
+			o.hashed[strings.ToLower(k)] = struct{}{}
+		}
+	}
+}
+
+// hash replaces the values of hashed keys found in keyValues.
+func (o *options) hash(keyValues []interface{}) {
+	if len(o.hashed) == 0 {
+		return
+	}
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		if _, ok := o.hashed[strings.ToLower(keyString(keyValues[i]))]; ok {
+			keyValues[i+1] = o.hashValue(keyValues[i+1])
+		}
+	}
+}
+
+// hashValue returns the salted hash of the textual representation of v.
+func (o *options) hashValue(v interface{}) string {
+	mac := hmac.New(sha256.New, o.hashSalt)
+	_, _ = fmt.Fprint(mac, v)
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
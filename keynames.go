@@ -0,0 +1,58 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+// KeyNames holds the keys of the built-in timestamp, message, level and error
+// fields.
+type KeyNames struct {
+	Time    string
+	Message string
+	Level   string
+	Error   string
+}
+
+// defaultKeyNames holds the default keys of the built-in fields.
+var defaultKeyNames = KeyNames{
+	Time:    "ts",
+	Message: "msg",
+	Level:   "level",
+	Error:   "error",
+}
+
+// WithKeyNames sets the keys of the built-in fields, allowing output to match
+// downstream schemas such as ECS or GCP without post processing, e.g.:
+//
+//	logger.WithKeyNames(logger.KeyNames{Message: "message", Level: "severity"})
+//
+// Empty names keep their default: "ts", "msg", "level" and "error". Custom
+// Encoders receive the Entry and are responsible for their own key names. If
+// combined with WithKitLevels, a custom level key is not recognized by Go kit
+// level filters.
+func WithKeyNames(k KeyNames) Option {
+	return func(o *options) {
+		if k.Time != "" {
+			o.keys.Time = k.Time
+		}
+		if k.Message != "" {
+			o.keys.Message = k.Message
+		}
+		if k.Level != "" {
+			o.keys.Level = k.Level
+		}
+		if k.Error != "" {
+			o.keys.Error = k.Error
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-kit/log"
+)
+
+// Precedence determines the order in which key-value pairs from the different
+// sources are merged into a record. Parsers which keep the first occurrence
+// of a duplicate key will use the value from the source merged first, while
+// parsers keeping the last occurrence use the value from the source merged
+// last.
+type Precedence int
+
+// Available merge precedences.
+const (
+	// ContextFirst merges the pairs found in Context first, followed by the
+	// pairs added through With and the pairs provided at the call site.
+	ContextFirst Precedence = iota
+	// CallSiteFirst merges the pairs provided at the call site first,
+	// followed by the pairs added through With and the pairs found in Context.
+	CallSiteFirst
+)
+
+// mergeKeyValues merges the key-value pairs of the provided sources in the
+// order dictated by precedence. A dangling key of any source is paired with
+// log.ErrMissingValue, in line with With, so it can't shift the pairs of the
+// sources merged after it.
+func mergeKeyValues(p Precedence, ctx, with, callSite []interface{}) []interface{} {
+	args := make([]interface{}, 0, len(ctx)+len(with)+len(callSite)+3)
+	if p == CallSiteFirst {
+		args = appendPadded(args, callSite)
+		args = appendPadded(args, with)
+		return appendPadded(args, ctx)
+	}
+	args = appendPadded(args, ctx)
+	args = appendPadded(args, with)
+	return appendPadded(args, callSite)
+}
+
+// appendPadded appends keyValues to args, pairing a dangling key with
+// log.ErrMissingValue.
+func appendPadded(args, keyValues []interface{}) []interface{} {
+	args = append(args, keyValues...)
+	if len(keyValues)%2 != 0 {
+		args = append(args, log.ErrMissingValue)
+	}
+	return args
+}
+
+// DuplicatePolicy determines how key-value pairs sharing the same key are
+// handled. Which occurrence is considered first is dictated by Precedence.
+type DuplicatePolicy int
+
+// Available duplicate key policies.
+const (
+	// EmitAll emits all pairs, including the ones with duplicate keys.
+	EmitAll DuplicatePolicy = iota
+	// FirstWins only emits the first occurrence of a key.
+	FirstWins
+	// LastWins emits the value of the last occurrence of a key at the position
+	// of its first occurrence.
+	LastWins
+	// SuffixDedupe emits all pairs, renaming duplicate keys by adding a numeric
+	// suffix, e.g. key, key_2, key_3.
+	SuffixDedupe
+)
+
+// dedupeKeyValues applies the provided DuplicatePolicy to keyValues. The
+// provided slice is modified in place and the result is returned.
+func dedupeKeyValues(p DuplicatePolicy, keyValues []interface{}) []interface{} {
+	if p == EmitAll || len(keyValues) < 4 {
+		return keyValues
+	}
+	var (
+		seen = make(map[string]int, len(keyValues)/2)
+		out  = keyValues[:0]
+	)
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		key, value := keyValues[i], keyValues[i+1]
+		k := keyString(key)
+		idx, dup := seen[k]
+		switch {
+		case !dup:
+			seen[k] = len(out)
+			out = append(out, key, value)
+		case p == LastWins:
+			out[idx+1] = value
+		case p == SuffixDedupe:
+			n := 2
+			for {
+				if _, taken := seen[fmt.Sprintf("%s_%d", k, n)]; !taken {
+					break
+				}
+				n++
+			}
+			k = fmt.Sprintf("%s_%d", k, n)
+			seen[k] = len(out)
+			out = append(out, k, value)
+		}
+	}
+	return out
+}
+
+// keyString returns the string representation of a key-value pair's key.
+func keyString(key interface{}) string {
+	if k, ok := key.(string); ok {
+		return k
+	}
+	return fmt.Sprint(key)
+}
+
+// sortKeyValues sorts the key-value pairs alphabetically by key. Pairs sharing
+// the same key retain their relative order.
+func sortKeyValues(keyValues []interface{}) {
+	pairs := make([][2]interface{}, 0, len(keyValues)/2)
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		pairs = append(pairs, [2]interface{}{keyValues[i], keyValues[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return keyString(pairs[i][0]) < keyString(pairs[j][0])
+	})
+	for i, p := range pairs {
+		keyValues[2*i], keyValues[2*i+1] = p[0], p[1]
+	}
+}
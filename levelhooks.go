@@ -0,0 +1,87 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "sync"
+
+// LevelChangeFunc is called with the previous and new level after the log
+// level of a Logger changed.
+type LevelChangeFunc func(old, new Level)
+
+// levelHooks holds the callbacks of a log level shared by a Logger and the
+// Loggers derived from it, or of all levels managed by a ScopeManager.
+type levelHooks struct {
+	mtx   sync.Mutex
+	seq   uint64
+	funcs []levelHook
+}
+
+type levelHook struct {
+	id uint64
+	fn func(scope string, old, new Level)
+}
+
+// add registers fn and returns a function removing it again.
+func (h *levelHooks) add(fn func(scope string, old, new Level)) (remove func()) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	h.seq++
+	id := h.seq
+	h.funcs = append(h.funcs, levelHook{id: id, fn: fn})
+
+	return func() {
+		h.mtx.Lock()
+		defer h.mtx.Unlock()
+
+		for i, hook := range h.funcs {
+			if hook.id == id {
+				h.funcs = append(h.funcs[:i:i], h.funcs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notify invokes all registered callbacks in order of registration.
+func (h *levelHooks) notify(scope string, old, new Level) {
+	h.mtx.Lock()
+	funcs := h.funcs
+	h.mtx.Unlock()
+
+	for _, hook := range funcs {
+		hook.fn(scope, old, new)
+	}
+}
+
+// OnLevelChange registers fn to be called each time SetLevel changes the log
+// level of l. As the log level is shared with Loggers derived through With,
+// Context and Metric, changes made through any of them are reported as well.
+// Loggers created through WithDetachedLevel start without callbacks. The
+// callback is invoked synchronously from SetLevel and must not block. The
+// returned function removes the callback.
+func (l *Logger) OnLevelChange(fn LevelChangeFunc) (remove func()) {
+	return l.hooks.add(func(_ string, old, new Level) {
+		fn(old, new)
+	})
+}
+
+// OnLevelChange registers fn to be called each time the log level of the
+// default logger or one of the registered scopes changes. The scope name
+// provided to fn is "default" for the default logger. The returned function
+// removes the callback.
+func (s *ScopeManager) OnLevelChange(fn func(scope string, old, new Level)) (remove func()) {
+	return s.hooks.add(fn)
+}
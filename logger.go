@@ -17,6 +17,7 @@ package logger
 import (
 	"context"
 	"io"
+	"os"
 	"sync/atomic"
 
 	"github.com/go-kit/log"
@@ -33,78 +34,220 @@ type Level int32
 const (
 	None  Level = 0
 	Error Level = 1
+	Warn  Level = 3
 	Info  Level = 5
 	Debug Level = 10
+	// Trace is beyond the range of Debug verbosity levels as used by V,
+	// which ends at Debug + MaxVerbosity.
+	Trace Level = 1000
 )
 
 var levelToString = map[Level]string{
 	None:  "none",
 	Error: "error",
+	Warn:  "warn",
 	Info:  "info",
 	Debug: "debug",
+	Trace: "trace",
 }
 
 var stringToLevel = map[string]Level{
 	"none":  None,
 	"error": Error,
+	"warn":  Warn,
 	"info":  Info,
 	"debug": Debug,
+	"trace": Trace,
 }
 
 // Logger implements the telemetry.Logger interface using Go kit Log.
+//
+// Fields of a log line are emitted in a guaranteed order: the built-in msg,
+// level and (for Error records) error fields come first, followed by the
+// key-value pairs found in the attached Context, the pairs added through With
+// (followed by those computed by field providers) and finally the pairs
+// provided at the call site. WithPrecedence can be used to reverse the merge
+// order of these sources, WithDuplicatePolicy to control the handling of keys
+// shared between them and WithSortedKeys to sort all but the built-in fields
+// alphabetically by key.
 type Logger struct {
 	// ctx holds the Context to extract key-value pairs from to be added to each
 	// log line.
 	ctx context.Context
 	// args holds the key-value pairs to be added to each log line.
 	args []interface{}
-	// metric holds the Metric to increment each time Info() or Error() is called.
+	// metric holds the Metric to increment each time Info(), Warn() or Error()
+	// is called.
 	metric telemetry.Metric
 	// lvl holds the configured log level.
 	lvl *int32
-	// logger holds the Go kit logger to use.
-	logger log.Logger
+	// hooks holds the callbacks invoked when lvl changes.
+	hooks *levelHooks
+	// providers holds the field providers invoked for each emitted record.
+	providers []FieldProvider
+	// noContext disables the extraction of key-value pairs from ctx.
+	noContext bool
+	// verbosity holds the Debug sub-level at which Debug and Info records are
+	// emitted.
+	verbosity int
+	// sink holds the destination of emitted entries.
+	sink sink
+	// opts holds the configuration shared with derived Loggers.
+	opts *options
+	// scope holds the name of the scope as registered with a ScopeManager.
+	scope string
+	// retention optionally holds the retention class of the Logger, shared
+	// with the Loggers derived from it.
+	retention *atomic.Value
+	// pooled is set for Loggers obtained from LoggerBuilder.Acquire.
+	pooled bool
 }
 
 // New returns a new telemetry.Logger implementation based on Go kit log.
-func New(logger log.Logger) *Logger {
-	lvl := int32(Info)
-	return &Logger{
-		ctx:    context.Background(),
-		lvl:    &lvl,
-		logger: logger,
+// If logger is nil, a logfmt logger writing to stderr is used instead and
+// ErrNilLogger is reported to the ErrorHandler.
+func New(logger log.Logger, opts ...Option) *Logger {
+	return newKitLogger(logger, newOptions(opts))
+}
+
+// newKitLogger returns a new Logger emitting to the provided Go kit logger.
+func newKitLogger(logger log.Logger, o *options) *Logger {
+	if logger == nil {
+		o.reportError(ErrNilLogger)
+		logger = newSyncLogger(log.NewLogfmtLogger(os.Stderr))
+	}
+	return newLogger(newKitSink(logger, o), o)
+}
+
+// NewWithEncoder returns a new telemetry.Logger implementation which encodes
+// entries with the provided Encoder and writes the result to w. Writes are
+// synchronized, allowing the Logger to be used concurrently.
+func NewWithEncoder(w io.Writer, enc Encoder, opts ...Option) *Logger {
+	o := newOptions(opts)
+	return newLogger(&encoderSink{w: o.measure(w), enc: enc}, o)
+}
+
+// newLogger returns a new Logger emitting to the provided sink, applying the
+// sink related options.
+func newLogger(s sink, o *options) *Logger {
+	if o.asyncQueueSize > 0 {
+		s = newAsyncSink(s, o)
+	}
+	if len(o.pipeline) > 0 {
+		s = newPipelineSink(s, o.pipeline)
 	}
+	lvl := int32(o.level)
+	l := &Logger{
+		ctx:   context.Background(),
+		lvl:   &lvl,
+		hooks: &levelHooks{},
+		sink:  s,
+		opts:  o,
+	}
+	if o.banner {
+		l.emitBanner()
+	}
+	return l
 }
 
 // NewSyncLogfmt returns a new telemetry.Logger implementation using Go kit's
-// sync writer and logfmt output format.
-func NewSyncLogfmt(w io.Writer) *Logger {
-	return New(log.NewSyncLogger(log.NewLogfmtLogger(w)))
+// sync writer and logfmt output format. If w is nil, stderr is used instead
+// and ErrNilWriter is reported to the ErrorHandler.
+func NewSyncLogfmt(w io.Writer, opts ...Option) *Logger {
+	o := newOptions(opts)
+	if isNilWriter(w) {
+		o.reportError(ErrNilWriter)
+		w = os.Stderr
+	}
+	return newLogger(newKitSink(newSyncLogger(log.NewLogfmtLogger(o.measure(w))), o), o)
+}
+
+// NewSyncJSON returns a new telemetry.Logger implementation using Go kit's
+// sync writer and JSON output format. If w is nil, stderr is used instead and
+// ErrNilWriter is reported to the ErrorHandler.
+func NewSyncJSON(w io.Writer, opts ...Option) *Logger {
+	o := newOptions(opts)
+	if isNilWriter(w) {
+		o.reportError(ErrNilWriter)
+		w = os.Stderr
+	}
+	return newLogger(newKitSink(newSyncLogger(log.NewJSONLogger(o.measure(w))), o), o)
 }
 
 // SetLevel provides the ability to set the desired logging level.
 // This function can be used at runtime and is safe for concurrent use.
+// The provided level is subject to the ClampPolicy of the Logger, see
+// WithClampPolicy. Levels rejected by the policy leave the level untouched,
+// use ApplyLevel to obtain the applied level and rejection errors. Callbacks
+// registered through OnLevelChange are invoked if the level changed.
+// Setting the level to None suppresses all records.
 func (l *Logger) SetLevel(lvl Level) {
-	if lvl < Info {
-		lvl = Error
-	} else if lvl < Debug {
-		lvl = Info
-	} else {
-		lvl = Debug
+	_, _ = l.ApplyLevel(lvl)
+}
+
+// ApplyLevel sets the log level like SetLevel, returning the level applied
+// under the ClampPolicy of the Logger, or an error if the policy rejected lvl.
+func (l *Logger) ApplyLevel(lvl Level) (Level, error) {
+	lvl, err := l.opts.clampPolicy.clamp(lvl)
+	if err != nil {
+		return l.Level(), err
+	}
+	l.storeLevel(lvl)
+	return lvl, nil
+}
+
+// SetRawLevel sets the log level to lvl verbatim, regardless of the
+// ClampPolicy of the Logger, allowing custom intermediate levels to be used.
+// Records are emitted if their level is numerically at or below lvl. Negative
+// levels are treated as None.
+func (l *Logger) SetRawLevel(lvl Level) {
+	lvl, _ = ClampNone.clamp(lvl)
+	l.storeLevel(lvl)
+}
+
+// storeLevel stores lvl and invokes the OnLevelChange callbacks if the level
+// changed.
+func (l *Logger) storeLevel(lvl Level) {
+	if old := Level(atomic.SwapInt32(l.lvl, int32(lvl))); old != lvl {
+		l.hooks.notify("", old, lvl)
 	}
-	atomic.StoreInt32(l.lvl, int32(lvl))
+}
+
+// Level returns the currently configured log level.
+// This function is safe for concurrent use.
+func (l *Logger) Level() Level {
+	return Level(atomic.LoadInt32(l.lvl))
+}
+
+// Enabled returns true if records at the provided level are emitted by l. It
+// allows callers to skip the construction of expensive key-value pairs for
+// suppressed records. A level stored in the attached Context through
+// ContextWithLevel takes precedence over the configured level. Records at
+// None, or below, are never emitted, e.g. when demoted to None through
+// LevelOverride.
+func (l *Logger) Enabled(lvl Level) bool {
+	if lvl <= None {
+		return false
+	}
+	return l.threshold() >= lvl || l.boosted(lvl)
+}
+
+// DebugEnabled returns true if Debug records are emitted by l, taking the
+// verbosity level of l into account.
+func (l *Logger) DebugEnabled() bool {
+	return l.Enabled(l.debugLevel())
 }
 
 // Debug logging with key-value pairs. Don't be shy, use it.
 func (l *Logger) Debug(msg string, keyValues ...interface{}) {
-	if atomic.LoadInt32(l.lvl) < int32(Debug) {
-		return
-	}
-	args := []interface{}{"msg", msg, "level", "debug"}
-	args = append(args, telemetry.KeyValuesFromContext(l.ctx)...)
-	args = append(args, l.args...)
-	args = append(args, keyValues...)
-	_ = l.logger.Log(args...)
+	l.log(l.debugLevel(), msg, nil, keyValues)
+}
+
+// Trace logging with key-value pairs. This is intended for extremely verbose,
+// high-volume diagnostics. Trace is more verbose than all Debug verbosity
+// levels as used by V, so enabling any of them does not enable Trace output.
+func (l *Logger) Trace(msg string, keyValues ...interface{}) {
+	l.log(Trace, msg, nil, keyValues)
 }
 
 // Info logging with key-value pairs. This is for informational, but not
@@ -119,14 +262,18 @@ func (l *Logger) Info(msg string, keyValues ...interface{}) {
 	if l.metric != nil {
 		l.metric.RecordContext(l.ctx, 1)
 	}
-	if atomic.LoadInt32(l.lvl) < int32(Info) {
-		return
+	l.log(l.infoLevel(), msg, nil, keyValues)
+}
+
+// Warn logging with key-value pairs. Use this for conditions which are not
+// errors but might require attention, such as degraded operation or retried
+// failures. As with Info, it is highly recommended to attach a Metric to these
+// types of messages.
+func (l *Logger) Warn(msg string, keyValues ...interface{}) {
+	if l.metric != nil {
+		l.metric.RecordContext(l.ctx, 1)
 	}
-	args := []interface{}{"msg", msg, "level", "info"}
-	args = append(args, telemetry.KeyValuesFromContext(l.ctx)...)
-	args = append(args, l.args...)
-	args = append(args, keyValues...)
-	_ = l.logger.Log(args...)
+	l.log(Warn, msg, nil, keyValues)
 }
 
 // Error logging with key-value pairs. Use this when application state and
@@ -139,14 +286,115 @@ func (l *Logger) Error(msg string, err error, keyValues ...interface{}) {
 	if l.metric != nil {
 		l.metric.RecordContext(l.ctx, 1)
 	}
-	if atomic.LoadInt32(l.lvl) < int32(Error) {
+	l.log(Error, msg, err, keyValues)
+}
+
+// log emits a record at the provided level if enabled. It must be called
+// directly from the exported logging methods, keeping the stack depth at
+// which Valuers are invoked stable.
+func (l *Logger) log(lvl Level, msg string, err error, keyValues []interface{}) {
+	if override, ok := l.levelOverride(keyValues); ok {
+		lvl = override
+	}
+	lvl, keyValues = l.escalated(lvl, msg, keyValues)
+	if !l.Enabled(lvl) {
+		if lvl > None && lvl <= Error && l.opts.suppressed != nil {
+			l.reportSuppressed()
+		}
 		return
 	}
-	args := []interface{}{"msg", msg, "level", "error", "error", err}
-	args = append(args, telemetry.KeyValuesFromContext(l.ctx)...)
-	args = append(args, l.args...)
-	args = append(args, keyValues...)
-	_ = l.logger.Log(args...)
+	if l.opts.reentrancyGuard && l.reentrant() {
+		return
+	}
+	if l.opts.sampler != nil && l.opts.sampler.Decide(lvl, msg, keyValues) == SampleDrop {
+		return
+	}
+	// pairs describing the calling site, skipping log and the exported
+	// logging method, are added to the call-site pairs so they are subject to
+	// deduplication, hashing, redaction and sorting like any other pair
+	var site []interface{}
+	if l.opts.fingerprint && err != nil && lvl <= Error {
+		site = append(site, "error.fingerprint", fingerprint(2, msg, err))
+	}
+	if l.opts.stackDepth > 0 && lvl > None && lvl <= Error {
+		site = append(site, "stacktrace", l.opts.stacktrace(2))
+	}
+	if l.opts.caller {
+		site = append(site, "caller", caller(2+l.opts.callerSkip))
+	}
+	if l.opts.callDepth {
+		site = append(site, "call_depth", callDepth(2))
+	}
+	if l.opts.callerChain > 0 {
+		site = append(site, "caller_chain", CaptureStack(2, l.opts.callerChain))
+	}
+	if len(site) > 0 {
+		args := make([]interface{}, 0, len(keyValues)+1+len(site))
+		keyValues = append(appendPadded(args, keyValues), site...)
+	}
+	e := l.entry(lvl, msg, err, keyValues)
+	_ = l.sink.emit(e)
+	l.mirrorSpanEvent(e)
+}
+
+// entry returns a new Entry holding the provided record details and all
+// key-value pairs found in the attached Context and added through With.
+func (l *Logger) entry(lvl Level, msg string, err error, keyValues []interface{}) *Entry {
+	args := mergeKeyValues(l.opts.precedence,
+		l.contextKeyValues(), l.withKeyValues(), keyValues)
+	args = removeLevelOverride(args)
+	bindValues(args)
+	args = dedupeKeyValues(l.opts.duplicates, args)
+	args = l.addRetention(lvl, args)
+	if l.opts.recordID != nil {
+		args = append(args, RecordIDKey, l.opts.recordID())
+	}
+	l.opts.hash(args)
+	l.opts.redact(args)
+	if l.opts.sortKeys {
+		sortKeyValues(args)
+	}
+	e := &Entry{
+		Level:     lvl,
+		Time:      l.opts.clock.Now(),
+		Message:   msg,
+		Error:     err,
+		KeyValues: args,
+	}
+	l.opts.formatErrors(e)
+	return e
+}
+
+// contextKeyValues returns the key-value pairs found in the attached Context,
+// unless extraction is disabled through NoContext.
+func (l *Logger) contextKeyValues() []interface{} {
+	if l.noContext {
+		return nil
+	}
+	return telemetry.KeyValuesFromContext(l.ctx)
+}
+
+// NoContext returns a Logger which skips the extraction of key-value pairs
+// from the attached Context. This is intended for extremely hot code paths
+// which intentionally don't want ambient fields on their log lines. The
+// Context is still used for Metrics labels.
+func (l *Logger) NoContext() *Logger {
+	newLogger := l.clone(0)
+	newLogger.noContext = true
+
+	return newLogger
+}
+
+// bindValues replaces all Go kit log.Valuer values in keyValues with their
+// generated values, evaluating them lazily per record. As Valuers are invoked
+// at a stack depth of 5 from the calling site of Debug, Info and Error, use
+// log.Caller(5) instead of log.DefaultCaller.
+func bindValues(keyValues []interface{}) {
+	for i := 1; i < len(keyValues); i += 2 {
+		if v, ok := keyValues[i].(log.Valuer); ok {
+			keyValues[i] = v()
+		}
+	}
 }
 
 // With returns Logger with provided key value pairs attached.
@@ -154,23 +402,60 @@ func (l *Logger) With(keyValues ...interface{}) telemetry.Logger {
 	if len(keyValues) == 0 {
 		return l
 	}
+	newLogger := l.clone(len(keyValues) + 1)
+	newLogger.args = appendKeyValues(newLogger.args, l.opts.validateKeyValues(keyValues))
+
+	return newLogger
+}
+
+// appendKeyValues appends the key-value pairs with string keys, as well as the
+// LevelOverride key, found in keyValues to args. A dangling key is paired with
+// log.ErrMissingValue.
+func appendKeyValues(args []interface{}, keyValues []interface{}) []interface{} {
 	if len(keyValues)%2 != 0 {
-		keyValues = append(keyValues, "(MISSING)")
-	}
-	newLogger := &Logger{
-		args:   make([]interface{}, len(l.args), len(l.args)+len(keyValues)),
-		ctx:    l.ctx,
-		metric: l.metric,
-		logger: l.logger,
-		lvl:    l.lvl,
+		keyValues = append(keyValues, log.ErrMissingValue)
 	}
-	copy(newLogger.args, l.args)
-
+	markLevelOverride(keyValues)
 	for i := 0; i < len(keyValues); i += 2 {
-		if k, ok := keyValues[i].(string); ok {
-			newLogger.args = append(newLogger.args, k, keyValues[i+1])
+		switch k := keyValues[i].(type) {
+		case string, levelOverrideKey:
+			args = append(args, k, keyValues[i+1])
 		}
 	}
+	return args
+}
+
+// pairValue returns the value of the key-value pair starting at index i of
+// keyValues, or log.ErrMissingValue for a dangling key. Encoders use it, as
+// entries passed to them need not hold complete pairs.
+func pairValue(keyValues []interface{}, i int) interface{} {
+	if i+1 < len(keyValues) {
+		return keyValues[i+1]
+	}
+	return log.ErrMissingValue
+}
+
+// WithDetachedLevel returns a Logger with its own independent log level,
+// initialized to the current level of l. By default, Loggers derived through
+// With, Context and Metric share the level of their parent, causing SetLevel
+// on a derived Logger to change verbosity for all of them.
+func (l *Logger) WithDetachedLevel() *Logger {
+	lvl := atomic.LoadInt32(l.lvl)
+	newLogger := l.clone(0)
+	newLogger.lvl = &lvl
+	newLogger.hooks = &levelHooks{}
+
+	return newLogger
+}
+
+// WithLevel returns a Logger with its own independent log level set to lvl,
+// allowing noisy subsystems to be silenced individually. See
+// WithDetachedLevel. The level is subject to the ClampPolicy of the Logger;
+// levels rejected by ClampStrict leave the level of l in place.
+func (l *Logger) WithLevel(lvl Level) *Logger {
+	newLogger := l.WithDetachedLevel()
+	newLogger.SetLevel(lvl)
+
 	return newLogger
 }
 
@@ -179,36 +464,37 @@ func (l *Logger) With(keyValues ...interface{}) telemetry.Logger {
 // their own. This allows for different logger implementations to collaborate,
 // if they are simultaneously present in an application.
 func (l *Logger) KeyValuesToContext(ctx context.Context, keyValues ...interface{}) context.Context {
+	markLevelOverride(keyValues)
 	return telemetry.KeyValuesToContext(ctx, keyValues...)
 }
 
 // Context attaches provided Context to the Logger allowing metadata found in
-// this context to be used for log lines and metrics labels.
+// this context to be used for log lines and metrics labels. A nil Context is
+// substituted with context.Background, see WithNilContextReporting.
 func (l *Logger) Context(ctx context.Context) telemetry.Logger {
-	newLogger := &Logger{
-		args:   make([]interface{}, len(l.args), len(l.args)),
-		ctx:    ctx,
-		metric: l.metric,
-		logger: l.logger,
-		lvl:    l.lvl,
-	}
-	copy(newLogger.args, l.args)
+	newLogger := l.clone(0)
+	newLogger.ctx = l.opts.checkContext(ctx)
 
 	return newLogger
 }
 
 // Metric attaches provided Metric to the Logger allowing this metric to
-// record each invocation of Info and Error log lines. If context is available
-// in the logger, it can be used for Metrics labels.
+// record each invocation of Info, Warn and Error log lines. If context is
+// available in the logger, it can be used for Metrics labels.
 func (l *Logger) Metric(m telemetry.Metric) telemetry.Logger {
-	newLogger := &Logger{
-		args:   make([]interface{}, len(l.args), len(l.args)),
-		ctx:    l.ctx,
-		metric: m,
-		logger: l.logger,
-		lvl:    l.lvl,
-	}
-	copy(newLogger.args, l.args)
+	newLogger := l.clone(0)
+	newLogger.metric = l.scopedMetric(m)
 
 	return newLogger
 }
+
+// clone returns a copy of l with room for extra additional key-value pair
+// items in its args.
+func (l *Logger) clone(extra int) *Logger {
+	newLogger := *l
+	newLogger.pooled = false
+	newLogger.args = make([]interface{}, len(l.args), len(l.args)+extra)
+	copy(newLogger.args, l.args)
+
+	return &newLogger
+}
@@ -0,0 +1,86 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"net/http"
+	"sync"
+)
+
+// maxPooledArgs holds the maximum capacity of key-value pair slices retained
+// by the Logger pool.
+const maxPooledArgs = 64
+
+var loggerPool = sync.Pool{
+	New: func() interface{} {
+		return new(Logger)
+	},
+}
+
+// Acquire returns the Logger holding all accumulated settings, taken from a
+// package level pool instead of being allocated. This eliminates a Logger
+// allocation on hot paths with a well defined, synchronous lifetime.
+//
+// The returned Logger must be handed back with Release once done, after which
+// it must no longer be used. It must therefore not be stored in a Context or
+// otherwise be shared with goroutines which might outlive the call to
+// Release. Loggers derived from it through With, Context or Metric are
+// independent copies and remain usable after Release.
+func (b LoggerBuilder) Acquire() *Logger {
+	newLogger := loggerPool.Get().(*Logger)
+	args := newLogger.args[:0]
+	if b.args != nil {
+		args = append(args, b.args...)
+	} else {
+		args = append(args, b.parent.args...)
+	}
+	*newLogger = *b.parent
+	newLogger.args = args
+	newLogger.ctx = b.ctx
+	newLogger.metric = b.metric
+	newLogger.pooled = true
+
+	return newLogger
+}
+
+// Release returns a Logger obtained from LoggerBuilder.Acquire to the pool.
+// Calling Release on any other Logger is a no-op.
+func (l *Logger) Release() {
+	if l == nil || !l.pooled {
+		return
+	}
+	args := l.args
+	for i := range args {
+		args[i] = nil
+	}
+	*l = Logger{}
+	if cap(args) <= maxPooledArgs {
+		l.args = args[:0]
+	}
+	loggerPool.Put(l)
+}
+
+// Middleware returns HTTP middleware which attaches a request-scoped Logger,
+// derived from l and holding the request Context, as ambient Logger to the
+// request Context. See NewContext and FromContext. As the Logger is reachable
+// through the request Context, it is not pooled and remains valid for
+// goroutines outliving the request.
+func (l *Logger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rl := l.Context(r.Context())
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), rl)))
+	})
+}
@@ -0,0 +1,237 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logtest provides a harness of fake sinks with fault injection,
+// allowing users to integration-test their logging configuration and failure
+// handling.
+package logtest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDisconnected is returned by a Writer with the Disconnected fault set.
+var ErrDisconnected = errors.New("logtest: sink disconnected")
+
+// Faults configures the faults injected by the fake sinks.
+type Faults struct {
+	// Latency delays each write (Writer) or read (Server).
+	Latency time.Duration
+	// PartialWrites makes each write only consume half of the provided data
+	// and return io.ErrShortWrite (Writer), or makes the Server keep only the
+	// first half of the next record and drop the connection, as if the write
+	// was cut off mid-record (Server).
+	PartialWrites bool
+	// Disconnected makes each write fail with ErrDisconnected (Writer), or
+	// makes the Server close new connections right away (Server).
+	Disconnected bool
+}
+
+// Writer is a fake file sink capturing all written data in memory.
+type Writer struct {
+	mtx    sync.Mutex
+	faults Faults
+	buf    bytes.Buffer
+}
+
+// NewWriter returns a new fake file sink.
+func NewWriter() *Writer {
+	return &Writer{}
+}
+
+// SetFaults sets the faults to inject in subsequent writes.
+func (w *Writer) SetFaults(f Faults) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	w.faults = f
+}
+
+// Write implements io.Writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mtx.Lock()
+	f := w.faults
+	w.mtx.Unlock()
+
+	if f.Latency > 0 {
+		time.Sleep(f.Latency)
+	}
+	if f.Disconnected {
+		return 0, ErrDisconnected
+	}
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if f.PartialWrites {
+		n, _ := w.buf.Write(p[:len(p)/2])
+		return n, io.ErrShortWrite
+	}
+	return w.buf.Write(p)
+}
+
+// Lines returns the captured records, one per line.
+func (w *Writer) Lines() []string {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	return splitLines(w.buf.String())
+}
+
+// Reset discards all captured data.
+func (w *Writer) Reset() {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	w.buf.Reset()
+}
+
+// Server is a fake TCP network sink capturing all received records.
+type Server struct {
+	ln net.Listener
+	wg sync.WaitGroup
+
+	mtx    sync.Mutex
+	faults Faults
+	conns  map[net.Conn]struct{}
+	lines  []string
+}
+
+// NewServer starts a new fake network sink listening on a random local port.
+func NewServer() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		ln:    ln,
+		conns: make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.accept()
+
+	return s, nil
+}
+
+// Addr returns the address the Server listens on.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// SetFaults sets the faults to inject in subsequent reads and connections.
+func (s *Server) SetFaults(f Faults) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.faults = f
+}
+
+// Disconnect closes all active client connections.
+func (s *Server) Disconnect() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for c := range s.conns {
+		_ = c.Close()
+	}
+}
+
+// Lines returns the received records, one per line.
+func (s *Server) Lines() []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return append([]string(nil), s.lines...)
+}
+
+// Close stops the Server and closes all active client connections.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	s.Disconnect()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mtx.Lock()
+		if s.faults.Disconnected {
+			s.mtx.Unlock()
+			_ = c.Close()
+			continue
+		}
+		s.conns[c] = struct{}{}
+		s.mtx.Unlock()
+
+		s.wg.Add(1)
+		go s.serve(c)
+	}
+}
+
+func (s *Server) serve(c net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mtx.Lock()
+		delete(s.conns, c)
+		s.mtx.Unlock()
+		_ = c.Close()
+	}()
+
+	r := bufio.NewReader(c)
+	for {
+		s.mtx.Lock()
+		latency := s.faults.Latency
+		s.mtx.Unlock()
+		if latency > 0 {
+			time.Sleep(latency)
+		}
+
+		line, err := r.ReadString('\n')
+		s.mtx.Lock()
+		partial := s.faults.PartialWrites
+		s.mtx.Unlock()
+		if partial {
+			line = line[:len(line)/2]
+			err = io.ErrShortWrite
+		}
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			s.mtx.Lock()
+			s.lines = append(s.lines, line)
+			s.mtx.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
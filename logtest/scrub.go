@@ -0,0 +1,99 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logtest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Scrubber rewrites volatile fields in captured logfmt and JSON records to
+// fixed placeholders, making snapshot assertions stable across runs.
+type Scrubber struct {
+	rules []rule
+}
+
+type rule struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// uuidPattern matches canonical UUIDs.
+var uuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// NewScrubber returns a Scrubber without any rules.
+func NewScrubber() *Scrubber {
+	return &Scrubber{}
+}
+
+// DefaultScrubber returns a Scrubber replacing the values of the ts, time and
+// duration keys as well as UUIDs.
+func DefaultScrubber() *Scrubber {
+	return NewScrubber().
+		Key("ts", "<ts>").
+		Key("time", "<time>").
+		Key("duration", "<duration>").
+		Pattern(uuidPattern, "<uuid>")
+}
+
+// Key replaces the value of the provided key with placeholder.
+func (s *Scrubber) Key(key, placeholder string) *Scrubber {
+	k := regexp.QuoteMeta(key)
+	logfmtValue := placeholder
+	if regexp.MustCompile(`[\s="]`).MatchString(placeholder) {
+		logfmtValue = strconv.Quote(placeholder)
+	}
+	jsonValue := strconv.Quote(placeholder)
+	s.rules = append(s.rules,
+		rule{
+			re:   regexp.MustCompile(`(^|\s)` + k + `=(?:"(?:[^"\\]|\\.)*"|\S*)`),
+			repl: "${1}" + escapeRepl(key+"="+logfmtValue),
+		},
+		rule{
+			re:   regexp.MustCompile(`"` + k + `":\s*(?:"(?:[^"\\]|\\.)*"|[^,}\]]*)`),
+			repl: escapeRepl(strconv.Quote(key) + ":" + jsonValue),
+		},
+	)
+	return s
+}
+
+// Pattern replaces all matches of re with placeholder.
+func (s *Scrubber) Pattern(re *regexp.Regexp, placeholder string) *Scrubber {
+	s.rules = append(s.rules, rule{re: re, repl: escapeRepl(placeholder)})
+	return s
+}
+
+// Scrub returns the provided record with all rules applied.
+func (s *Scrubber) Scrub(line string) string {
+	for _, r := range s.rules {
+		line = r.re.ReplaceAllString(line, r.repl)
+	}
+	return line
+}
+
+// ScrubLines returns the provided records with all rules applied.
+func (s *Scrubber) ScrubLines(lines []string) []string {
+	res := make([]string, 0, len(lines))
+	for _, line := range lines {
+		res = append(res, s.Scrub(line))
+	}
+	return res
+}
+
+// escapeRepl escapes s for literal use in a regexp replacement template.
+func escapeRepl(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
@@ -0,0 +1,170 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"io"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/tetratelabs/multierror"
+)
+
+// Sink describes an output destination of a Logger writing to multiple
+// destinations, each with its own output format.
+type Sink struct {
+	// Writer holds the destination to write encoded records to.
+	Writer io.Writer
+	// Encoder holds the Encoder to use for Writer. If nil, Go kit's logfmt
+	// format is used.
+	Encoder Encoder
+	// Logger optionally holds a Go kit logger to use instead of Writer and
+	// Encoder.
+	Logger log.Logger
+	// WriteTimeout optionally holds the maximum duration of a single write to
+	// Writer. See NewTimeoutWriter.
+	WriteTimeout time.Duration
+	// Level optionally holds the minimum level of records written to this
+	// destination, evaluated after the level of the Logger. This allows e.g.
+	// a console at Info next to a file at Debug. If zero, all records passing
+	// the level of the Logger are written.
+	Level Level
+}
+
+// newSink returns the internal sink implementation for s.
+func (s Sink) newSink(o *options) sink {
+	if s.WriteTimeout > 0 && s.Writer != nil {
+		s.Writer = NewTimeoutWriter(s.Writer, s.WriteTimeout)
+	}
+	s.Writer = o.measure(s.Writer)
+	var res sink
+	switch {
+	case s.Logger != nil:
+		res = newKitSink(s.Logger, o)
+	case s.Encoder != nil:
+		res = &encoderSink{w: s.Writer, enc: s.Encoder}
+	default:
+		res = newKitSink(newSyncLogger(log.NewLogfmtLogger(s.Writer)), o)
+	}
+	if s.Level > None {
+		res = levelSink{sink: res, lvl: s.Level}
+	}
+	return res
+}
+
+// levelSink emits the entries at or below its level to sink.
+type levelSink struct {
+	sink sink
+	lvl  Level
+}
+
+func (s levelSink) emit(entries ...*Entry) error {
+	filtered := entries[:0:0]
+	for _, e := range entries {
+		if e.Level <= s.lvl {
+			filtered = append(filtered, e)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	return s.sink.emit(filtered...)
+}
+
+// accepts reports whether s takes entries at lvl, as opposed to filtering
+// them by level.
+func accepts(s sink, lvl Level) bool {
+	if ls, ok := s.(levelSink); ok {
+		return lvl <= ls.lvl && accepts(ls.sink, lvl)
+	}
+	return true
+}
+
+// MultiMode determines how a Logger created by NewMulti distributes records
+// over its sinks.
+type MultiMode int
+
+// Available multi sink modes.
+const (
+	// Broadcast writes each record to all sinks. This is the default mode.
+	Broadcast MultiMode = iota
+	// Failover writes each record to the first sink, in order of priority,
+	// which accepts it. Lower priority sinks are only used if writing to the
+	// higher priority sinks failed.
+	Failover
+)
+
+// WithMultiMode sets the MultiMode of a Logger created by NewMulti.
+func WithMultiMode(m MultiMode) Option {
+	return func(o *options) {
+		o.multiMode = m
+	}
+}
+
+// NewMulti returns a new telemetry.Logger implementation which tees each
+// record to all provided sinks, allowing each sink its own output format.
+// With the Failover MultiMode, sinks are instead treated as priority ordered
+// fallbacks.
+func NewMulti(sinks []Sink, opts ...Option) *Logger {
+	o := newOptions(opts)
+	list := make([]sink, 0, len(sinks))
+	for _, s := range sinks {
+		list = append(list, s.newSink(o))
+	}
+	if o.multiMode == Failover {
+		return newLogger(failoverSink(list), o)
+	}
+	return newLogger(teeSink(list), o)
+}
+
+// teeSink emits entries to all of its sinks.
+type teeSink []sink
+
+func (t teeSink) emit(entries ...*Entry) error {
+	var mErr error
+	for _, s := range t {
+		if err := s.emit(entries...); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr
+}
+
+// failoverSink emits each entry to the first of its sinks accepting it. Sinks
+// filtering the level of an entry are skipped, rather than counting as having
+// taken it.
+type failoverSink []sink
+
+func (f failoverSink) emit(entries ...*Entry) error {
+	var mErr error
+	for _, e := range entries {
+		var eErr error
+		for _, s := range f {
+			if !accepts(s, e.Level) {
+				continue
+			}
+			err := s.emit(e)
+			if err == nil {
+				eErr = nil
+				break
+			}
+			eErr = multierror.Append(eErr, err)
+		}
+		if eErr != nil {
+			mErr = multierror.Append(mErr, eErr)
+		}
+	}
+	return mErr
+}
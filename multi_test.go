@@ -0,0 +1,79 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tetratelabs/telemetry-gokit-log/logtest"
+)
+
+func TestFailoverSkipsFilteringSinks(t *testing.T) {
+	primary, secondary := logtest.NewWriter(), logtest.NewWriter()
+	l := NewMulti([]Sink{
+		{Writer: primary, Level: Warn},
+		{Writer: secondary},
+	}, WithMultiMode(Failover))
+
+	l.Info("info")
+	l.Error("error", errors.New("failed"))
+
+	if lines := primary.Lines(); len(lines) != 1 {
+		t.Errorf("expected the error record in primary, got %q", lines)
+	}
+	if lines := secondary.Lines(); len(lines) != 1 {
+		t.Errorf("expected the info record in secondary, got %q", lines)
+	}
+}
+
+func TestFailoverOnError(t *testing.T) {
+	primary, secondary := logtest.NewWriter(), logtest.NewWriter()
+	l := NewMulti([]Sink{
+		{Writer: primary},
+		{Writer: secondary},
+	}, WithMultiMode(Failover))
+
+	primary.SetFaults(logtest.Faults{Disconnected: true})
+	l.Info("failover")
+	primary.SetFaults(logtest.Faults{})
+	l.Info("primary")
+
+	if lines := primary.Lines(); len(lines) != 1 {
+		t.Errorf("expected 1 record in primary, got %q", lines)
+	}
+	if lines := secondary.Lines(); len(lines) != 1 {
+		t.Errorf("expected 1 record in secondary, got %q", lines)
+	}
+}
+
+func TestBroadcastSinkLevels(t *testing.T) {
+	console, file := logtest.NewWriter(), logtest.NewWriter()
+	l := NewMulti([]Sink{
+		{Writer: console, Level: Info},
+		{Writer: file},
+	})
+	l.SetLevel(Debug)
+
+	l.Debug("debug")
+	l.Info("info")
+
+	if lines := console.Lines(); len(lines) != 1 {
+		t.Errorf("expected 1 record in console, got %q", lines)
+	}
+	if lines := file.Lines(); len(lines) != 2 {
+		t.Errorf("expected 2 records in file, got %q", lines)
+	}
+}
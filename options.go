@@ -0,0 +1,184 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"os"
+	"time"
+
+	"github.com/tetratelabs/telemetry"
+)
+
+// Option allows for functional options to our Logger.
+type Option func(*options)
+
+// options holds the configuration shared by a Logger and all Loggers derived
+// from it.
+type options struct {
+	// clock holds the time source of the Logger.
+	clock Clock
+	// errorFormatter holds the optional renderer for error values.
+	errorFormatter ErrorFormatter
+	// sortKeys enables alphabetical sorting of key-value pairs.
+	sortKeys bool
+	// precedence holds the merge order of key-value pair sources.
+	precedence Precedence
+	// duplicates holds the policy for handling duplicate keys.
+	duplicates DuplicatePolicy
+	// suppressed holds the optional counter of suppressed Error records.
+	suppressed *suppressionSummary
+	// sampler holds the optional Sampler.
+	sampler Sampler
+	// spanEvents holds the optional span event mirror.
+	spanEvents SpanEventFunc
+	// asyncQueueSize holds the queue size of async mode, if enabled.
+	asyncQueueSize int
+	// queueLatency enables the queue_latency_ms field in async mode.
+	queueLatency bool
+	// fingerprint enables the error.fingerprint field.
+	fingerprint bool
+	// redacted holds the lowercased keys whose values are redacted.
+	redacted map[string]struct{}
+	// clampPolicy holds the policy applied by SetLevel.
+	clampPolicy ClampPolicy
+	// exit holds the function called by Fatal.
+	exit func(code int)
+	// errorHandler holds the optional handler of construction problems.
+	errorHandler ErrorHandler
+	// sizeMetric holds the optional Metric recording encoded record sizes.
+	sizeMetric telemetry.Metric
+	// boost holds the optional per-request debug boost.
+	boost BoostFunc
+	// validate enables the validation of key-value pairs in With.
+	validate bool
+	// multiMode holds the distribution mode of NewMulti.
+	multiMode MultiMode
+	// scopeLabel holds the optional Metric dimension holding the scope name.
+	scopeLabel telemetry.Label
+	// kitLevels enables Go kit level values for Go kit loggers.
+	kitLevels bool
+	// pipeline holds the Stages processing emitted records.
+	pipeline []Stage
+	// hashed holds the lowercased keys whose values are hashed.
+	hashed map[string]struct{}
+	// hashSalt holds the salt used for hashing values.
+	hashSalt []byte
+	// level holds the initial log level.
+	level Level
+	// banner enables the configuration banner record.
+	banner bool
+	// reportNilContext enables reporting of nil Contexts.
+	reportNilContext bool
+	// keys holds the keys of the built-in fields.
+	keys KeyNames
+	// timeFormatter holds the optional renderer of the timestamp field.
+	timeFormatter TimeFormatter
+	// timeLocation holds the time zone of the timestamp field.
+	timeLocation *time.Location
+	// callDepth enables the call_depth field.
+	callDepth bool
+	// callerChain holds the number of frames of the caller_chain field.
+	callerChain int
+	// reentrancyGuard enables the detection of re-entrant logging.
+	reentrancyGuard bool
+	// reentrancyNotices counts the reported re-entrant records.
+	reentrancyNotices int32
+	// caller enables the caller field.
+	caller bool
+	// callerSkip holds the number of additional frames to skip for caller.
+	callerSkip int
+	// stackDepth holds the number of frames of the stacktrace field.
+	stackDepth int
+	// stackFormatter holds the optional renderer of the stacktrace field.
+	stackFormatter StackFormatter
+	// durationMetric holds the optional Metric recording Timed durations.
+	durationMetric telemetry.Metric
+	// escalation holds the optional escalation policy.
+	escalation *escalation
+	// retention holds the default retention classes per level.
+	retention map[Level]string
+	// recordID holds the optional generator of the record_id field.
+	recordID IDGenerator
+	// color optionally enables or disables colors of the console format.
+	color *bool
+	// multiline enables the multi-line console format.
+	multiline bool
+	// invalid holds the errors of rejected option values, reported to the
+	// ErrorHandler once all options are applied.
+	invalid []error
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		clock:        systemClock{},
+		exit:         os.Exit,
+		level:        Info,
+		keys:         defaultKeyNames,
+		timeLocation: time.UTC,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	for _, err := range o.invalid {
+		o.reportError(err)
+	}
+	o.invalid = nil
+	return o
+}
+
+// WithClock sets the Clock used by the Logger. If not provided the system
+// clock is used.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		if c != nil {
+			o.clock = c
+		}
+	}
+}
+
+// WithErrorFormatter sets the ErrorFormatter used to render the error of Error
+// records as well as error values found in key-value pairs.
+func WithErrorFormatter(f ErrorFormatter) Option {
+	return func(o *options) {
+		o.errorFormatter = f
+	}
+}
+
+// WithSortedKeys sorts the key-value pairs of each record alphabetically by
+// key, providing stable output for diff-based tooling. The built-in message,
+// level and error fields are not affected and are always emitted first.
+func WithSortedKeys() Option {
+	return func(o *options) {
+		o.sortKeys = true
+	}
+}
+
+// WithPrecedence sets the order in which key-value pairs found in Context,
+// added through With and provided at the call site are merged. By default
+// Context pairs come first.
+func WithPrecedence(p Precedence) Option {
+	return func(o *options) {
+		o.precedence = p
+	}
+}
+
+// WithDuplicatePolicy sets the policy applied when key-value pairs found in
+// Context, added through With and provided at the call site share keys. By
+// default all pairs are emitted.
+func WithDuplicatePolicy(p DuplicatePolicy) Option {
+	return func(o *options) {
+		o.duplicates = p
+	}
+}
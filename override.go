@@ -0,0 +1,107 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "sync/atomic"
+
+// levelOverrideKey is the type of the LevelOverride key. Using a dedicated
+// type prevents collisions with user provided keys.
+type levelOverrideKey struct{}
+
+// LevelOverride is a reserved key which forces a record to the Level provided
+// as its value, e.g.:
+//
+//	l.Info("slow request", logger.LevelOverride, logger.Error, "took", d)
+//
+// This allows generic middleware to escalate (or demote) selected records
+// without separate code paths. The key is accepted at the call site, through
+// With and in Context. Call site pairs take precedence over pairs added
+// through With, which in turn take precedence over pairs found in Context.
+// The pair itself is never emitted. Overriding the level to None drops the
+// record.
+//
+// To keep disabled records cheap, pairs added through With and pairs in
+// Context are only consulted once the key has been passed to With or to the
+// KeyValuesToContext method of a Logger. Pairs placed in Context through
+// other means are ignored until then.
+var LevelOverride interface{} = levelOverrideKey{}
+
+// levelOverridesUsed is set once the LevelOverride key has been passed to
+// With or KeyValuesToContext, avoiding the lookup in the With and Context
+// pairs on each record for applications not using the feature.
+var levelOverridesUsed int32
+
+// markLevelOverride sets levelOverridesUsed if keyValues holds the
+// LevelOverride key.
+func markLevelOverride(keyValues []interface{}) {
+	if atomic.LoadInt32(&levelOverridesUsed) != 0 {
+		return
+	}
+	for i := 0; i < len(keyValues); i += 2 {
+		if _, ok := keyValues[i].(levelOverrideKey); ok {
+			atomic.StoreInt32(&levelOverridesUsed, 1)
+			return
+		}
+	}
+}
+
+// levelOverride returns the Level found for the LevelOverride key, if any.
+func (l *Logger) levelOverride(keyValues []interface{}) (Level, bool) {
+	if lvl, ok := findLevelOverride(keyValues); ok {
+		return lvl, true
+	}
+	if atomic.LoadInt32(&levelOverridesUsed) == 0 {
+		return None, false
+	}
+	if lvl, ok := findLevelOverride(l.args); ok {
+		return lvl, true
+	}
+	return findLevelOverride(l.contextKeyValues())
+}
+
+// findLevelOverride returns the value of the last LevelOverride pair found in
+// keyValues. Values can be of type Level or a textual level representation.
+func findLevelOverride(keyValues []interface{}) (lvl Level, found bool) {
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		if _, ok := keyValues[i].(levelOverrideKey); !ok {
+			continue
+		}
+		switch v := keyValues[i+1].(type) {
+		case Level:
+			lvl, found = v, true
+		case string:
+			if parsed, ok := parseLevel(v); ok {
+				lvl, found = parsed, true
+			}
+		}
+	}
+	return lvl, found
+}
+
+// removeLevelOverride removes all LevelOverride pairs from keyValues. The
+// provided slice is modified in place and the result is returned.
+func removeLevelOverride(keyValues []interface{}) []interface{} {
+	out := keyValues[:0]
+	for i := 0; i < len(keyValues); i += 2 {
+		if _, ok := keyValues[i].(levelOverrideKey); ok {
+			continue
+		}
+		out = append(out, keyValues[i])
+		if i+1 < len(keyValues) {
+			out = append(out, keyValues[i+1])
+		}
+	}
+	return out
+}
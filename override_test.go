@@ -0,0 +1,75 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tetratelabs/telemetry-gokit-log/logtest"
+)
+
+func TestLevelOverride(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l *Logger)
+		want string
+	}{
+		{"promote", func(l *Logger) { l.Debug("msg", LevelOverride, Info) }, "level=info"},
+		{"demote", func(l *Logger) { l.Info("msg", LevelOverride, Debug) }, ""},
+		{"none", func(l *Logger) { l.Error("msg", nil, LevelOverride, None) }, ""},
+		{"negative", func(l *Logger) { l.Error("msg", nil, LevelOverride, Level(-1)) }, ""},
+		{"string", func(l *Logger) { l.Error("msg", nil, LevelOverride, "none") }, ""},
+		{"with", func(l *Logger) { l.With(LevelOverride, Warn).Debug("msg") }, "level=warn"},
+		{"call site over with", func(l *Logger) {
+			l.With(LevelOverride, Warn).Debug("msg", LevelOverride, None)
+		}, ""},
+		{"context", func(l *Logger) {
+			ctx := l.KeyValuesToContext(context.Background(), LevelOverride, Info)
+			l.Context(ctx).Debug("msg")
+		}, "level=info"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := logtest.NewWriter()
+			tt.log(NewSyncLogfmt(w))
+
+			lines := w.Lines()
+			if tt.want == "" {
+				if len(lines) != 0 {
+					t.Fatalf("expected the record to be dropped, got %q", lines)
+				}
+				return
+			}
+			if len(lines) != 1 {
+				t.Fatalf("expected 1 record, got %q", lines)
+			}
+			if !strings.Contains(lines[0], tt.want) {
+				t.Errorf("expected %q in %q", tt.want, lines[0])
+			}
+		})
+	}
+}
+
+func TestEnabledNone(t *testing.T) {
+	l := NewSyncLogfmt(logtest.NewWriter())
+	l.SetLevel(Trace)
+	for _, lvl := range []Level{None, Level(-1)} {
+		if l.Enabled(lvl) {
+			t.Errorf("expected level %d to be disabled", lvl)
+		}
+	}
+}
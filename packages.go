@@ -0,0 +1,162 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/tetratelabs/multierror"
+)
+
+// RegisterPackage returns a scoped Logger named after the import path of the
+// calling package, e.g. "github.com/ourorg/storage". On first registration,
+// the scope starts at the level set for the longest matching import path
+// prefix through SetPackageOutputLevel, if any, so verbosity can follow code
+// ownership boundaries without wiring scope names through the code base.
+// Import paths are case sensitive and, as the dots in them don't denote scope
+// hierarchy, package scopes don't inherit levels from dotted scopes.
+func (s *ScopeManager) RegisterPackage(description string) *Logger {
+	name := callerPackage(1)
+	l, existed := s.register(name, description, true)
+
+	s.mtx.Lock()
+	lvl, ok := s.packageLevel(name)
+	s.mtx.Unlock()
+
+	if ok && !existed {
+		_ = s.SetScopeOutputLevel(name, lvl)
+	}
+	return l
+}
+
+// SetPackageOutputLevel sets the minimum log output level for scopes
+// registered through RegisterPackage whose import path equals prefix or is
+// nested below it. For each scope, the longest matching prefix applies.
+// Levels set through SetScopeOutputLevel are overridden, levels set through
+// SetDefaultOutputLevel are not.
+func (s *ScopeManager) SetPackageOutputLevel(prefix string, lvl Level) error {
+	prefix = strings.Trim(prefix, "\r\n\t /")
+	if prefix == "" {
+		return fmt.Errorf("empty import path prefix")
+	}
+	lvl, err := s.logger.opts.clampPolicy.clamp(lvl)
+	if err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	if s.packageLevels == nil {
+		s.packageLevels = make(map[string]Level)
+	}
+	s.packageLevels[prefix] = lvl
+	s.mtx.Unlock()
+
+	return s.applyPackageLevels()
+}
+
+// parsePackageOutputLevels parses the comma-separated <prefix>:<level> pairs
+// of the log-package-output-level flag.
+func (s *ScopeManager) parsePackageOutputLevels() error {
+	var mErr error
+	for _, pl := range strings.Split(s.packageOutputLevels, ",") {
+		if strings.TrimSpace(pl) == "" {
+			continue
+		}
+		i := strings.LastIndexByte(pl, ':')
+		if i < 0 {
+			mErr = multierror.Append(mErr, fmt.Errorf("%q is not a valid <prefix>:<level> pair", pl))
+			continue
+		}
+		lvl, ok := parseLevel(pl[i+1:])
+		if !ok {
+			mErr = multierror.Append(mErr, fmt.Errorf("%q is not a valid log level", pl))
+			continue
+		}
+		if err := s.SetPackageOutputLevel(pl[:i], lvl); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr
+}
+
+// applyPackageLevels sets the level of all package scopes matching a prefix
+// set through SetPackageOutputLevel.
+func (s *ScopeManager) applyPackageLevels() error {
+	type update struct {
+		name string
+		lvl  Level
+	}
+	var updates []update
+	s.mtx.Lock()
+	for name, sc := range s.registry {
+		if !sc.pkg {
+			continue
+		}
+		if lvl, ok := s.packageLevel(name); ok {
+			updates = append(updates, update{name: name, lvl: lvl})
+		}
+	}
+	s.mtx.Unlock()
+
+	var mErr error
+	for _, u := range updates {
+		if err := s.SetScopeOutputLevel(u.name, u.lvl); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr
+}
+
+// packageLevel returns the level of the longest prefix matching importPath.
+// It must be called with s.mtx held.
+func (s *ScopeManager) packageLevel(importPath string) (Level, bool) {
+	var (
+		match string
+		lvl   Level
+		found bool
+	)
+	for prefix, l := range s.packageLevels {
+		if importPath != prefix && !strings.HasPrefix(importPath, prefix+"/") {
+			continue
+		}
+		if !found || len(prefix) > len(match) {
+			match, lvl, found = prefix, l, true
+		}
+	}
+	return lvl, found
+}
+
+// callerPackage returns the import path of the package of the calling
+// function. The argument skip is the number of stack frames to skip, with 0
+// identifying the caller of callerPackage.
+func callerPackage(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	// strip the function name, e.g. github.com/ourorg/storage.(*Store).Get
+	name := fn.Name()
+	i := strings.LastIndexByte(name, '/')
+	if j := strings.IndexByte(name[i+1:], '.'); j >= 0 {
+		name = name[:i+1+j]
+	}
+	// the runtime escapes dots in the last path element, e.g. gopkg.in/yaml%2ev3
+	return strings.ReplaceAll(name, "%2e", ".")
+}
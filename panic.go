@@ -0,0 +1,170 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Frame holds a single stack frame.
+type Frame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// String implements fmt.Stringer.
+func (f Frame) String() string {
+	return fmt.Sprintf("%s (%s:%d)", f.Func, f.File, f.Line)
+}
+
+// Stack holds a structured stack trace. It is rendered as a flat string by
+// text based formats and as a list of frames by JSON based formats.
+type Stack []Frame
+
+// String implements fmt.Stringer.
+func (s Stack) String() string {
+	frames := make([]string, 0, len(s))
+	for _, f := range s {
+		frames = append(frames, f.String())
+	}
+	return strings.Join(frames, "; ")
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Stack) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]Frame(s))
+}
+
+// CaptureStack returns the stack of the calling goroutine. The argument skip
+// is the number of stack frames to skip before recording, with 0 identifying
+// the caller of CaptureStack. At most depth frames are recorded.
+func CaptureStack(skip, depth int) Stack {
+	if depth <= 0 {
+		return nil
+	}
+	pc := make([]uintptr, depth)
+	n := runtime.Callers(skip+2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	stack := make(Stack, 0, n)
+	for {
+		f, more := frames.Next()
+		stack = append(stack, Frame{Func: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// StackFormatter renders a captured Stack into the value of a stack trace
+// field.
+type StackFormatter func(s Stack) interface{}
+
+// WithStacktrace adds a stacktrace field to Error records holding the stack
+// of the calling site, limited to depth frames, so crash forensics don't
+// require reproducing the failure. The stack is rendered by f. If f is nil,
+// the Stack is emitted as is, rendering as a flat string in text based formats
+// and as a list of frames in JSON based formats.
+func WithStacktrace(depth int, f StackFormatter) Option {
+	return func(o *options) {
+		o.stackDepth = depth
+		o.stackFormatter = f
+	}
+}
+
+// stacktrace returns the stack trace field value. The argument skip is the
+// number of stack frames to skip, with 0 identifying the caller of stacktrace.
+func (o *options) stacktrace(skip int) interface{} {
+	stack := CaptureStack(skip+1, o.stackDepth)
+	if o.stackFormatter == nil {
+		return stack
+	}
+	return o.stackFormatter(stack)
+}
+
+// maxPanicDepth holds the maximum number of frames recorded for panics.
+const maxPanicDepth = 64
+
+// Recovered logs a recovered panic value as an Error record. Next to the
+// provided key-value pairs, the record holds the type of the panic value and
+// a structured stack starting at the panicking frame, allowing crash
+// analytics to group by frame. It must be called from the deferred function
+// which recovered the panic:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			l.Recovered("handler panicked", r)
+//		}
+//	}()
+func (l *Logger) Recovered(msg string, r interface{}, keyValues ...interface{}) {
+	if l.metric != nil {
+		l.metric.RecordContext(l.ctx, 1)
+	}
+	// skip the deferred function and the runtime's panic handling frames
+	stack := CaptureStack(2, maxPanicDepth)
+	for len(stack) > 0 && strings.HasPrefix(stack[0].Func, "runtime.") {
+		stack = stack[1:]
+	}
+	err, ok := r.(error)
+	if !ok {
+		err = errors.New(fmt.Sprint(r))
+	}
+	args := make([]interface{}, 0, 4+len(keyValues))
+	args = append(args, "panic_type", fmt.Sprintf("%T", r), "stack", stack)
+	args = append(args, keyValues...)
+	l.log(Error, msg, err, args)
+}
+
+// PanicError is the value Panic panics with. It holds the details of the
+// logged record, allowing recovering code to inspect them.
+type PanicError struct {
+	// Message holds the message of the logged record.
+	Message string
+	// Err holds the error of the logged record, if any.
+	Err error
+	// KeyValues holds the key-value pairs provided at the call site.
+	KeyValues []interface{}
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *PanicError) Unwrap() error {
+	return e.Err
+}
+
+// Panic logs an Error record with key-value pairs, drains all registered
+// Flushers and then panics with a *PanicError holding the record details.
+// This allows library code to fail loudly on unrecoverable conditions while
+// still producing a well-formed log line.
+func (l *Logger) Panic(msg string, err error, keyValues ...interface{}) {
+	if l.metric != nil {
+		l.metric.RecordContext(l.ctx, 1)
+	}
+	l.log(Error, msg, err, keyValues)
+	_ = Sync()
+	panic(&PanicError{Message: msg, Err: err, KeyValues: keyValues})
+}
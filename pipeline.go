@@ -0,0 +1,125 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"strings"
+
+	"github.com/tetratelabs/multierror"
+)
+
+// EntryHandler processes an emitted Entry.
+type EntryHandler func(e *Entry) error
+
+// Stage is a step of a record pipeline. It returns an EntryHandler which
+// processes an Entry before handing it to next, or not handing it on at all
+// to drop it.
+type Stage func(next EntryHandler) EntryHandler
+
+// WithPipeline declares an ordered chain of Stages processing each emitted
+// record before it reaches the destination of the Logger, e.g.:
+//
+//	logger.WithPipeline(
+//		logger.Enrich(addHostname),
+//		logger.Redact("password"),
+//		logger.Sample(logger.NewBurstSampler(nil, time.Second, 10, 100)),
+//		logger.Route(isAudit, auditLogger),
+//	)
+//
+// Stages run in the order provided, after the built-in key-value handling,
+// redaction and sampling options. Repeated use appends to the chain.
+func WithPipeline(stages ...Stage) Option {
+	return func(o *options) {
+		o.pipeline = append(o.pipeline, stages...)
+	}
+}
+
+// Enrich returns a Stage invoking f for each Entry, allowing it to add or
+// modify key-value pairs.
+func Enrich(f func(e *Entry)) Stage {
+	return func(next EntryHandler) EntryHandler {
+		return func(e *Entry) error {
+			f(e)
+			return next(e)
+		}
+	}
+}
+
+// Redact returns a Stage replacing the values of the provided keys with
+// Redacted. Keys are matched case-insensitively.
+func Redact(keys ...string) Stage {
+	redacted := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		redacted[strings.ToLower(k)] = struct{}{}
+	}
+	return func(next EntryHandler) EntryHandler {
+		return func(e *Entry) error {
+			redactKeyValues(redacted, e.KeyValues)
+			return next(e)
+		}
+	}
+}
+
+// Sample returns a Stage dropping the entries for which s decides SampleDrop.
+func Sample(s Sampler) Stage {
+	return func(next EntryHandler) EntryHandler {
+		return func(e *Entry) error {
+			if s.Decide(e.Level, e.Message, e.KeyValues) == SampleDrop {
+				return nil
+			}
+			return next(e)
+		}
+	}
+}
+
+// Route returns a Stage sending the entries matched by match to the
+// destination of dst instead of the remainder of the pipeline. The level
+// configuration and options of dst are not applied.
+func Route(match func(e *Entry) bool, dst *Logger) Stage {
+	return func(next EntryHandler) EntryHandler {
+		return func(e *Entry) error {
+			if match(e) {
+				return dst.sink.emit(e)
+			}
+			return next(e)
+		}
+	}
+}
+
+// pipelineSink hands entries to a chain of Stages terminating in sink.
+type pipelineSink struct {
+	sink    sink
+	handler EntryHandler
+}
+
+func newPipelineSink(s sink, stages []Stage) pipelineSink {
+	handler := EntryHandler(func(e *Entry) error {
+		return s.emit(e)
+	})
+	for i := len(stages) - 1; i >= 0; i-- {
+		handler = stages[i](handler)
+	}
+	return pipelineSink{sink: s, handler: handler}
+}
+
+func (p pipelineSink) emit(entries ...*Entry) error {
+	var mErr error
+	for _, e := range entries {
+		if err := p.handler(e); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr
+}
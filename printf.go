@@ -0,0 +1,85 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "fmt"
+
+// The printf-style logging methods ease migrating from the standard library
+// log package. Records flow through the same level configuration, Context and
+// Metric handling as their key-value counterparts, without key-value pairs
+// attached at the call site. Messages are only rendered if the record is
+// enabled, so disabled records are not counted by WithEscalation.
+
+// Debugf logs a Debug record with a message rendered using fmt.Sprintf. The
+// message is only rendered if Debug output is enabled.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	lvl := l.debugLevel()
+	if !l.printfEnabled(lvl) {
+		return
+	}
+	l.log(lvl, fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Infof logs an Info record with a message rendered using fmt.Sprintf. The
+// message is only rendered if Info output is enabled.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	if l.metric != nil {
+		l.metric.RecordContext(l.ctx, 1)
+	}
+	lvl := l.infoLevel()
+	if !l.printfEnabled(lvl) {
+		return
+	}
+	l.log(lvl, fmt.Sprintf(format, args...), nil, nil)
+}
+
+// Errorf logs an Error record with a message rendered using fmt.Sprintf. The
+// first error found in args, if any, is used as the error of the record. The
+// message is only rendered if Error output is enabled.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	if l.metric != nil {
+		l.metric.RecordContext(l.ctx, 1)
+	}
+	if !l.printfEnabled(Error) {
+		return
+	}
+	l.log(Error, fmt.Sprintf(format, args...), firstError(args), nil)
+}
+
+// printfEnabled reports whether a printf-style record at lvl is enabled,
+// taking LevelOverride pairs added through With or found in Context into
+// account. Suppressed Error records are counted as log does.
+func (l *Logger) printfEnabled(lvl Level) bool {
+	if override, ok := l.levelOverride(nil); ok {
+		lvl = override
+	}
+	if l.Enabled(lvl) {
+		return true
+	}
+	if lvl > None && lvl <= Error && l.opts.suppressed != nil {
+		l.reportSuppressed()
+	}
+	return false
+}
+
+// firstError returns the first error found in args.
+func firstError(args []interface{}) error {
+	for _, arg := range args {
+		if err, ok := arg.(error); ok && err != nil {
+			return err
+		}
+	}
+	return nil
+}
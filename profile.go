@@ -0,0 +1,149 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+)
+
+// ProfileEnv holds the name of the environment variable consulted by
+// NewFromProfile if no profile name is provided.
+const ProfileEnv = "LOG_PROFILE"
+
+// Format is an enumeration of the built-in output formats.
+type Format int
+
+// Available output formats.
+const (
+	Logfmt Format = iota
+	JSON
+)
+
+// Profile bundles the logging defaults of an environment.
+type Profile struct {
+	// Format holds the output format.
+	Format Format
+	// Encoder optionally holds a custom Encoder, taking precedence over Format.
+	Encoder Encoder
+	// Level holds the initial log level, subject to the ClampPolicy. If not
+	// set, Info is used.
+	Level Level
+	// Options holds the options applied before the ones provided to
+	// NewFromProfile, e.g. sampling and redaction defaults.
+	Options []Option
+}
+
+// defaultRedactedKeys holds the keys redacted by the built-in staging and
+// production profiles.
+var defaultRedactedKeys = []string{"authorization", "password", "secret", "token"}
+
+var profiles = struct {
+	mtx  sync.RWMutex
+	list map[string]Profile
+}{
+	list: map[string]Profile{
+		"dev": {
+			Format: Logfmt,
+			Level:  Debug,
+		},
+		"staging": {
+			Format:  JSON,
+			Level:   Info,
+			Options: []Option{WithRedactedKeys(defaultRedactedKeys...)},
+		},
+		"prod": {
+			Format: JSON,
+			Level:  Info,
+			Options: []Option{
+				WithRedactedKeys(defaultRedactedKeys...),
+				withBurstSampling(time.Second, 100, 100),
+			},
+		},
+	},
+}
+
+// withBurstSampling creates a new burst Sampler for each Logger it is applied
+// to, using the Logger's Clock.
+func withBurstSampling(tick time.Duration, first, thereafter int) Option {
+	return func(o *options) {
+		o.sampler = NewBurstSampler(o.clock, tick, first, thereafter)
+	}
+}
+
+// RegisterProfile registers a custom Profile under the provided name,
+// replacing an existing Profile of the same name. The built-in profiles are
+// dev, staging and prod.
+func RegisterProfile(name string, p Profile) {
+	profiles.mtx.Lock()
+	defer profiles.mtx.Unlock()
+
+	profiles.list[strings.ToLower(name)] = p
+}
+
+// NewFromProfile returns a new telemetry.Logger implementation writing to w,
+// configured by the named Profile. If name is empty, the profile is taken from
+// the LOG_PROFILE environment variable, defaulting to prod, which redacts
+// sensitive keys and samples records. Options provided are applied after the
+// ones of the Profile. An error is returned if the profile is unknown or its
+// Level is rejected by the ClampPolicy. If w is nil, os.Stderr is used and
+// ErrNilWriter is reported to the ErrorHandler.
+func NewFromProfile(w io.Writer, name string, opts ...Option) (*Logger, error) {
+	if name == "" {
+		name = os.Getenv(ProfileEnv)
+	}
+	if name == "" {
+		name = "prod"
+	}
+	profiles.mtx.RLock()
+	p, ok := profiles.list[strings.ToLower(name)]
+	profiles.mtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("logging profile %q not found", name)
+	}
+
+	o := newOptions(append(append([]Option{}, p.Options...), opts...))
+	if p.Level == None {
+		p.Level = Info
+	}
+	lvl, err := o.clampPolicy.clamp(p.Level)
+	if err != nil {
+		return nil, fmt.Errorf("logging profile %q: %w", name, err)
+	}
+	o.level = lvl
+	if isNilWriter(w) {
+		o.reportError(ErrNilWriter)
+		w = os.Stderr
+	}
+	w = o.measure(w)
+
+	var s sink
+	switch {
+	case p.Encoder != nil:
+		s = &encoderSink{w: w, enc: p.Encoder}
+	case p.Format == JSON:
+		s = newKitSink(newSyncLogger(log.NewJSONLogger(w)), o)
+	default:
+		s = newKitSink(newSyncLogger(log.NewLogfmtLogger(w)), o)
+	}
+
+	return newLogger(s, o), nil
+}
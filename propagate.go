@@ -0,0 +1,103 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/tetratelabs/telemetry"
+)
+
+// Propagation maps the keys of accumulated key-value pairs to the names of
+// the headers or metadata entries used to propagate them to downstream
+// services.
+type Propagation map[string]string
+
+// KeyValues returns the key-value pairs accumulated by the Logger: the pairs
+// found in the attached Context followed by the pairs added through With.
+func (l *Logger) KeyValues() []interface{} {
+	ctxKeyValues := l.contextKeyValues()
+	args := make([]interface{}, 0, len(ctxKeyValues)+len(l.args))
+	args = append(args, ctxKeyValues...)
+	return append(args, l.args...)
+}
+
+// InjectHTTPHeaders sets the outgoing HTTP headers for the accumulated
+// key-value pairs selected by p. If a key occurs multiple times, the last
+// value is used.
+func (l *Logger) InjectHTTPHeaders(h http.Header, p Propagation) {
+	for name, value := range p.values(l.KeyValues()) {
+		h.Set(name, value)
+	}
+}
+
+// InjectMetadata sets the outgoing gRPC metadata entries for the accumulated
+// key-value pairs selected by p. The provided map is compatible with gRPC's
+// metadata.MD type. Metadata names are lowercased as required by gRPC.
+func (l *Logger) InjectMetadata(md map[string][]string, p Propagation) {
+	for name, value := range p.values(l.KeyValues()) {
+		md[strings.ToLower(name)] = []string{value}
+	}
+}
+
+// ContextFromHTTPHeaders returns a Context holding the key-value pairs found
+// in the incoming HTTP headers selected by p. It is the counterpart of
+// InjectHTTPHeaders for use in downstream services.
+func ContextFromHTTPHeaders(ctx context.Context, h http.Header, p Propagation) context.Context {
+	var keyValues []interface{}
+	for key, name := range p {
+		if value := h.Get(name); value != "" {
+			keyValues = append(keyValues, key, value)
+		}
+	}
+	return telemetry.KeyValuesToContext(ctx, keyValues...)
+}
+
+// ContextFromMetadata returns a Context holding the key-value pairs found in
+// the incoming gRPC metadata selected by p. It is the counterpart of
+// InjectMetadata for use in downstream services.
+func ContextFromMetadata(ctx context.Context, md map[string][]string, p Propagation) context.Context {
+	var keyValues []interface{}
+	for key, name := range p {
+		if values := md[strings.ToLower(name)]; len(values) > 0 {
+			keyValues = append(keyValues, key, values[0])
+		}
+	}
+	return telemetry.KeyValuesToContext(ctx, keyValues...)
+}
+
+// values returns the header names and string values for the key-value pairs
+// selected by p.
+func (p Propagation) values(keyValues []interface{}) map[string]string {
+	res := make(map[string]string, len(p))
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		k, ok := keyValues[i].(string)
+		if !ok {
+			continue
+		}
+		if name, ok := p[k]; ok {
+			value := keyValues[i+1]
+			if v, ok := value.(log.Valuer); ok {
+				value = v()
+			}
+			res[name] = fmt.Sprint(value)
+		}
+	}
+	return res
+}
@@ -0,0 +1,49 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+// FieldProvider computes key-value pairs to add to a record. Providers are
+// only invoked when a record will actually be emitted, making them suitable
+// for expensive enrichment which should never run for filtered records.
+type FieldProvider func() []interface{}
+
+// WithFieldProvider returns a Logger which invokes the provided FieldProvider
+// for each emitted record. The resulting pairs are added after the pairs
+// added through With.
+func (l *Logger) WithFieldProvider(p FieldProvider) *Logger {
+	if p == nil {
+		return l
+	}
+	newLogger := l.clone(0)
+	newLogger.providers = make([]FieldProvider, len(l.providers), len(l.providers)+1)
+	copy(newLogger.providers, l.providers)
+	newLogger.providers = append(newLogger.providers, p)
+
+	return newLogger
+}
+
+// withKeyValues returns the pairs added through With, followed by the pairs
+// computed by the Logger's field providers.
+func (l *Logger) withKeyValues() []interface{} {
+	if len(l.providers) == 0 {
+		return l.args
+	}
+	args := make([]interface{}, len(l.args), len(l.args)+2*len(l.providers))
+	copy(args, l.args)
+	for _, p := range l.providers {
+		args = appendKeyValues(args, p())
+	}
+	return args
+}
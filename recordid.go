@@ -0,0 +1,59 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RecordIDKey holds the key of the record id field.
+const RecordIDKey = "record_id"
+
+// IDGenerator returns a new unique identifier, e.g. a ULID, UUID or snowflake
+// id. It must be safe for concurrent use.
+type IDGenerator func() string
+
+// WithRecordID stamps each record with a unique record_id field generated by
+// gen, enabling exactly-once deduplication downstream when batches are
+// retried. If gen is nil, UUIDv4 is used.
+func WithRecordID(gen IDGenerator) Option {
+	return func(o *options) {
+		if gen == nil {
+			gen = UUIDv4
+		}
+		o.recordID = gen
+	}
+}
+
+// UUIDv4 returns a random RFC 4122 version 4 UUID.
+func UUIDv4() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+
+	var b [36]byte
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b[:])
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "strings"
+
+// Redacted holds the placeholder replacing the values of redacted keys.
+const Redacted = "[REDACTED]"
+
+// WithRedactedKeys replaces the values of the provided keys with Redacted.
+// Keys are matched case-insensitively.
+func WithRedactedKeys(keys ...string) Option {
+	return func(o *options) {
+		if o.redacted == nil {
+			o.redacted = make(map[string]struct{}, len(keys))
+		}
+		for _, k := range keys {
+			o.redacted[strings.ToLower(k)] = struct{}{}
+		}
+	}
+}
+
+// redact replaces the values of redacted keys found in keyValues.
+func (o *options) redact(keyValues []interface{}) {
+	redactKeyValues(o.redacted, keyValues)
+}
+
+// redactKeyValues replaces the values of the keys in keyValues found in the
+// provided set of lowercased keys.
+func redactKeyValues(redacted map[string]struct{}, keyValues []interface{}) {
+	if len(redacted) == 0 {
+		return
+	}
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		if _, ok := redacted[strings.ToLower(keyString(keyValues[i]))]; ok {
+			keyValues[i+1] = Redacted
+		}
+	}
+}
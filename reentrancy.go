@@ -0,0 +1,80 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+)
+
+// ErrReentrantLogging is reported to the ErrorHandler when a record emitted
+// from within the logging pipeline is dropped by the re-entrancy guard.
+var ErrReentrantLogging = errors.New("re-entrant logging detected, record dropped")
+
+const (
+	// maxReentrancyNotices holds the number of times ErrReentrantLogging is
+	// reported per Logger configuration.
+	maxReentrancyNotices = 10
+	// maxReentrancyFrames holds the number of frames inspected for
+	// re-entrant logging.
+	maxReentrancyFrames = 64
+)
+
+// logFuncName holds the name of the function through which all records pass.
+var logFuncName string
+
+func init() {
+	logFuncName = runtime.FuncForPC(reflect.ValueOf((*Logger).log).Pointer()).Name()
+}
+
+// WithReentrancyGuard drops records emitted from within the logging pipeline
+// of a Logger, e.g. by a failing sink, a Valuer, a field provider or a
+// pipeline Stage logging again. This breaks logging loops which would
+// otherwise end in a stack overflow. As detection is based on the call stack,
+// records logged through any Logger of this package while a record is being
+// emitted on the same goroutine are dropped, not only those logged through the
+// guarded Logger. Records logged from other goroutines, such as the background
+// goroutine of WithAsync, are not detected. Dropped records are reported as
+// ErrReentrantLogging to the ErrorHandler, at most 10 times. As the guard
+// inspects the call stack of each emitted record, it comes at a cost.
+func WithReentrancyGuard() Option {
+	return func(o *options) {
+		o.reentrancyGuard = true
+	}
+}
+
+// reentrant reports whether the calling log invocation is nested within
+// another one of any Logger on the current goroutine, reporting the first
+// occurrences to the ErrorHandler.
+func (l *Logger) reentrant() bool {
+	pc := make([]uintptr, maxReentrancyFrames)
+	// skip reentrant and the calling log invocation
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		f, more := frames.Next()
+		if f.Function == logFuncName {
+			if atomic.AddInt32(&l.opts.reentrancyNotices, 1) <= maxReentrancyNotices {
+				l.opts.reportError(ErrReentrantLogging)
+			}
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
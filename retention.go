@@ -0,0 +1,94 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// RetentionKey holds the key of the retention hint field, which downstream
+// pipelines can use to apply different retention policies, e.g. aging out
+// debug data faster than audit data. A retention class can be attached to a
+// single record by providing the key at the call site, e.g.:
+//
+//	l.Info("user deleted", logger.RetentionKey, "audit", "user", id)
+//
+// A class provided at the call site, through With or in Context takes
+// precedence over the class of the Logger or scope, see WithRetentionClass
+// and SetScopeRetention, which in turn takes precedence over the class of
+// the record level, see WithRetention.
+const RetentionKey = "retention_class"
+
+// WithRetention sets the default retention class per level, added as
+// retention_class field to records not holding a more specific class.
+func WithRetention(classes map[Level]string) Option {
+	return func(o *options) {
+		o.retention = make(map[Level]string, len(classes))
+		for lvl, class := range classes {
+			o.retention[lvl] = class
+		}
+	}
+}
+
+// WithRetentionClass returns a Logger adding the provided retention class to
+// all its records, unless overridden per record.
+func (l *Logger) WithRetentionClass(class string) *Logger {
+	newLogger := l.clone(0)
+	newLogger.retention = &atomic.Value{}
+	newLogger.retention.Store(class)
+
+	return newLogger
+}
+
+// SetScopeRetention sets the retention class of the records of a given scope
+// and the Loggers derived from it, unless overridden per record. An empty
+// class resets the scope to the retention classes of WithRetention.
+func (s *ScopeManager) SetScopeRetention(name, class string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	name = scopeName(name)
+	sc, has := s.registry[name]
+	if !has {
+		return fmt.Errorf("scope %q not found", name)
+	}
+	sc.logger.retention.Store(class)
+	return nil
+}
+
+// retentionClass returns the retention class of records at lvl.
+func (l *Logger) retentionClass(lvl Level) string {
+	if l.retention != nil {
+		if class, _ := l.retention.Load().(string); class != "" {
+			return class
+		}
+	}
+	return l.opts.retention[lvl]
+}
+
+// addRetention adds the retention_class field to keyValues, unless present.
+func (l *Logger) addRetention(lvl Level, keyValues []interface{}) []interface{} {
+	class := l.retentionClass(lvl)
+	if class == "" {
+		return keyValues
+	}
+	for i := 0; i < len(keyValues); i += 2 {
+		if k, ok := keyValues[i].(string); ok && k == RetentionKey {
+			return keyValues
+		}
+	}
+	return append(keyValues, RetentionKey, class)
+}
@@ -0,0 +1,110 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of a sampling decision.
+type Decision int
+
+// Available sampling decisions.
+const (
+	// SampleKeep emits the record.
+	SampleKeep Decision = iota
+	// SampleDrop suppresses the record.
+	SampleDrop
+)
+
+// Sampler decides whether a record passing the level configuration is
+// emitted. It allows for custom sampling strategies, such as hash based
+// sampling per request id or per tenant budgets. It is consulted before the
+// record is built, so dropped records don't pay for merging pairs, resolving
+// Valuers and running field providers. The provided key-value pairs therefore
+// only hold the raw pairs of the call site. Use the Sample Stage of a pipeline
+// for decisions based on the pairs found in Context or added through With.
+// Implementations must be safe for concurrent use.
+type Sampler interface {
+	Decide(lvl Level, msg string, keyValues []interface{}) Decision
+}
+
+// SamplerFunc is an adapter to allow the use of ordinary functions as Sampler.
+type SamplerFunc func(lvl Level, msg string, keyValues []interface{}) Decision
+
+// Decide implements Sampler.
+func (f SamplerFunc) Decide(lvl Level, msg string, keyValues []interface{}) Decision {
+	return f(lvl, msg, keyValues)
+}
+
+// WithSampler sets the Sampler consulted for each record passing the level
+// configuration.
+func WithSampler(s Sampler) Option {
+	return func(o *options) {
+		o.sampler = s
+	}
+}
+
+// burstSampler emits the first records of each message per tick, followed by
+// every nth record thereafter.
+type burstSampler struct {
+	clock      Clock
+	tick       time.Duration
+	first      int
+	thereafter int
+
+	mtx    sync.Mutex
+	start  time.Time
+	counts map[string]int
+}
+
+// NewBurstSampler returns a Sampler which, per message and per tick, keeps the
+// first records and every thereafter'th record after that. If thereafter is
+// zero or less, all records beyond first are dropped until the next tick. The
+// provided Clock is used for tick boundaries; if nil the system clock is used.
+func NewBurstSampler(clock Clock, tick time.Duration, first, thereafter int) Sampler {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &burstSampler{
+		clock:      clock,
+		tick:       tick,
+		first:      first,
+		thereafter: thereafter,
+		counts:     make(map[string]int),
+	}
+}
+
+func (s *burstSampler) Decide(_ Level, msg string, _ []interface{}) Decision {
+	now := s.clock.Now()
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if now.Sub(s.start) >= s.tick {
+		s.start = now
+		s.counts = make(map[string]int)
+	}
+	s.counts[msg]++
+	n := s.counts[msg]
+	if n <= s.first {
+		return SampleKeep
+	}
+	if s.thereafter > 0 && (n-s.first)%s.thereafter == 0 {
+		return SampleKeep
+	}
+	return SampleDrop
+}
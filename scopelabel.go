@@ -0,0 +1,37 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "github.com/tetratelabs/telemetry"
+
+// WithScopeLabel adds the scope name as dimension to Metrics attached to
+// scoped Loggers, as returned by ScopeManager.Register. This allows a single
+// Metric definition to be shared by all components while still providing a
+// per component breakdown. The label is inserted, so a value explicitly set on
+// the attached Metric takes precedence. Set this option on the Logger provided
+// to NewScopeManager.
+func WithScopeLabel(label telemetry.Label) Option {
+	return func(o *options) {
+		o.scopeLabel = label
+	}
+}
+
+// scopedMetric returns m with the scope label of l applied, if configured.
+func (l *Logger) scopedMetric(m telemetry.Metric) telemetry.Metric {
+	if m == nil || l.opts.scopeLabel == nil || l.scope == "" {
+		return m
+	}
+	return m.With(l.opts.scopeLabel.Insert(l.scope))
+}
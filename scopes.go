@@ -31,51 +31,96 @@ var _ run.Config = (*ScopeManager)(nil)
 
 // ScopeManager manages scoped loggers.
 type ScopeManager struct {
-	logger       *Logger
-	outputLevels string
+	logger              *Logger
+	outputLevels        string
+	packageOutputLevels string
 
-	mtx      sync.Mutex
-	registry map[string]*scopedLogger
+	mtx           sync.Mutex
+	registry      map[string]*scopedLogger
+	packageLevels map[string]Level
+	hooks         levelHooks
 }
 
 type scopedLogger struct {
 	name        string
 	description string
 	logger      *Logger
+	// explicit is set if the level was set for this scope specifically, as
+	// opposed to being inherited from a parent scope.
+	explicit bool
+	// pkg is set for scopes registered through RegisterPackage.
+	pkg bool
 }
 
 // NewScopeManager returns a new Scope Manager for Logger.
 func NewScopeManager(logger *Logger) *ScopeManager {
-	return &ScopeManager{
+	s := &ScopeManager{
 		logger:       logger,
-		outputLevels: levelToString[Level(atomic.LoadInt32(logger.lvl))],
+		outputLevels: levelString(logger.Level()),
 		registry:     make(map[string]*scopedLogger),
 	}
+	logger.OnLevelChange(func(old, new Level) {
+		s.hooks.notify("default", old, new)
+	})
+	return s
 }
 
 // Register takes a name and description and returns a scoped Logger.
+// Scope names can form a dotted hierarchy, e.g. "server.http.handlers". A new
+// scope starts at the level of its nearest registered parent scope, if any,
+// or the default level otherwise.
 func (s *ScopeManager) Register(name, description string) *Logger {
+	l, _ := s.register(scopeName(name), description, false)
+	return l
+}
+
+// register returns the Logger of the named scope, registering it if needed.
+// Package scopes, named after import paths, take no part in the dotted
+// hierarchy. It reports whether the scope already existed.
+func (s *ScopeManager) register(name, description string, pkg bool) (*Logger, bool) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
-	name = strings.ToLower(strings.Trim(name, "\r\n\t "))
 	scoped, ok := s.registry[name]
 	if ok {
-		return scoped.logger
+		scoped.pkg = scoped.pkg || pkg
+		return scoped.logger, true
 	}
 	lvl := atomic.LoadInt32(s.logger.lvl)
+	if parent := s.parent(name); parent != nil && !pkg {
+		lvl = atomic.LoadInt32(parent.logger.lvl)
+	}
 	scoped = &scopedLogger{
 		name:        name,
 		description: description,
+		pkg:         pkg,
 		logger: &Logger{
-			ctx:    context.Background(),
-			lvl:    &lvl,
-			logger: s.logger.logger,
+			ctx:       context.Background(),
+			lvl:       &lvl,
+			hooks:     &levelHooks{},
+			scope:     name,
+			retention: &atomic.Value{},
+			sink:      s.logger.sink,
+			opts:      s.logger.opts,
 		},
 	}
+	scoped.logger.OnLevelChange(func(old, new Level) {
+		s.hooks.notify(name, old, new)
+	})
 	s.registry[name] = scoped
 
-	return scoped.logger
+	return scoped.logger, false
+}
+
+// scopeName returns the normalized form of a scope name. Scope names are case
+// insensitive, except for import paths as used by RegisterPackage, which are
+// case sensitive.
+func scopeName(name string) string {
+	name = strings.Trim(name, "\r\n\t ")
+	if strings.IndexByte(name, '/') >= 0 {
+		return name
+	}
+	return strings.ToLower(name)
 }
 
 // Deregister will attempt to deregister a scoped Logger identified by the
@@ -84,7 +129,7 @@ func (s *ScopeManager) Deregister(name string) bool {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
-	name = strings.ToLower(strings.Trim(name, "\r\n\t "))
+	name = scopeName(name)
 	if _, has := s.registry[name]; !has {
 		return false
 	}
@@ -110,10 +155,15 @@ func (s *ScopeManager) FlagSet() *run.FlagSet {
 		"Comma-separated minimum per-scope logging level of messages to output, "+
 			"in the form of [default_level,]<scope>:<level>,<scope>:<level>,... "+
 			"where scope can be one of [%s] and default_level or level can be "+
-			"one of [%s, %s, %s]",
+			"one of [%s, %s, %s, %s, %s] or v<n> for Debug verbosity level n",
 		strings.Join(keys, ", "),
-		"debug", "info", "error",
+		"trace", "debug", "info", "warn", "error",
 	))
+	fs.StringVar(&s.packageOutputLevels, "log-package-output-level", s.packageOutputLevels,
+		"Comma-separated minimum logging level of messages to output for scopes "+
+			"registered by import path, in the form of <prefix>:<level>,... where "+
+			"prefix is an import path prefix, e.g. github.com/ourorg/storage:debug. "+
+			"Levels set through log-output-level take precedence")
 
 	return fs
 }
@@ -122,7 +172,10 @@ func (s *ScopeManager) FlagSet() *run.FlagSet {
 func (s *ScopeManager) Validate() error {
 	var mErr error
 
-	s.outputLevels = strings.ToLower(s.outputLevels)
+	if err := s.parsePackageOutputLevels(); err != nil {
+		mErr = multierror.Append(mErr, err)
+	}
+
 	outputLevels := strings.Split(s.outputLevels, ",")
 	if len(outputLevels) == 0 {
 		return nil
@@ -131,14 +184,14 @@ func (s *ScopeManager) Validate() error {
 		osl := strings.Split(ol, ":")
 		switch len(osl) {
 		case 1:
-			lvl, ok := stringToLevel[strings.Trim(ol, "\r\n\t ")]
+			lvl, ok := parseLevel(ol)
 			if !ok {
 				mErr = multierror.Append(mErr, fmt.Errorf("%q is not a valid log level", ol))
 				continue
 			}
 			s.SetDefaultOutputLevel(lvl)
 		case 2:
-			lvl, ok := stringToLevel[strings.Trim(osl[1], "\r\n\t ")]
+			lvl, ok := parseLevel(osl[1])
 			if !ok {
 				mErr = multierror.Append(mErr, fmt.Errorf("%q is not a valid log level", ol))
 				continue
@@ -154,45 +207,107 @@ func (s *ScopeManager) Validate() error {
 	return mErr
 }
 
-// SetDefaultOutputLevel sets the minimum log output level for all scopes.
+// SetDefaultOutputLevel sets the minimum log output level for all scopes,
+// clearing the levels set for specific scopes. Levels set for import path
+// prefixes through SetPackageOutputLevel are reapplied.
 func (s *ScopeManager) SetDefaultOutputLevel(lvl Level) {
 	// update base logger
 	s.logger.SetLevel(lvl)
 	// update all scoped loggers
+	s.mtx.Lock()
+	scopes := make([]*scopedLogger, 0, len(s.registry))
 	for _, sg := range s.registry {
+		sg.explicit = false
+		scopes = append(scopes, sg)
+	}
+	s.mtx.Unlock()
+	for _, sg := range scopes {
 		sg.logger.SetLevel(lvl)
 	}
+	_ = s.applyPackageLevels()
 }
 
 // SetScopeOutputLevel sets the minimum log output level for a given scope.
+// The level cascades to the child scopes of the dotted hierarchy, e.g. setting
+// the level of "server" also sets "server.http", unless a level was set for
+// the child scope, or one of the scopes in between, specifically.
 func (s *ScopeManager) SetScopeOutputLevel(name string, lvl Level) error {
 	s.mtx.Lock()
-	name = strings.ToLower(strings.Trim(name, "\r\n\t "))
+	name = scopeName(name)
 	sc, has := s.registry[name]
-	s.mtx.Unlock()
 	if !has {
+		s.mtx.Unlock()
 		return fmt.Errorf("scope %q not found", name)
 	}
+	sc.explicit = true
+	children := s.inheriting(name)
+	s.mtx.Unlock()
 
-	sc.logger.SetLevel(lvl)
+	lvl, err := sc.logger.ApplyLevel(lvl)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		child.logger.SetRawLevel(lvl)
+	}
 	return nil
 }
 
+// parent returns the nearest registered parent scope of name, if any.
+// Package scopes are never parents. It must be called with s.mtx held.
+func (s *ScopeManager) parent(name string) *scopedLogger {
+	for i := strings.LastIndexByte(name, '.'); i > 0; i = strings.LastIndexByte(name, '.') {
+		name = name[:i]
+		if sc, ok := s.registry[name]; ok && !sc.pkg {
+			return sc
+		}
+	}
+	return nil
+}
+
+// inheriting returns the child scopes of name which inherit its level: those
+// without a specifically set level on themselves or a scope in between.
+// Package scopes neither have nor are child scopes. It must be called with
+// s.mtx held.
+func (s *ScopeManager) inheriting(name string) []*scopedLogger {
+	if sc, ok := s.registry[name]; ok && sc.pkg {
+		return nil
+	}
+	var res []*scopedLogger
+	prefix := name + "."
+	for n, sc := range s.registry {
+		if sc.pkg || !strings.HasPrefix(n, prefix) {
+			continue
+		}
+		inherits := true
+		for p := n; len(p) > len(name); p = p[:strings.LastIndexByte(p, '.')] {
+			if anc, ok := s.registry[p]; ok && anc.explicit {
+				inherits = false
+				break
+			}
+		}
+		if inherits {
+			res = append(res, sc)
+		}
+	}
+	return res
+}
+
 // GetDefaultOutputLevel returns the default minimum output level for scopes.
 func (s *ScopeManager) GetDefaultOutputLevel() Level {
-	return Level(atomic.LoadInt32(s.logger.lvl))
+	return s.logger.Level()
 }
 
 // GetOutputLevel returns the minimum log output level for a given scope.
 func (s *ScopeManager) GetOutputLevel(name string) (Level, error) {
 	s.mtx.Lock()
-	name = strings.ToLower(strings.Trim(name, "\r\n\t "))
+	name = scopeName(name)
 	sc, has := s.registry[name]
 	s.mtx.Unlock()
 	if !has {
 		return None, fmt.Errorf("scope %q not found", name)
 	}
-	return Level(atomic.LoadInt32(sc.logger.lvl)), nil
+	return sc.logger.Level(), nil
 }
 
 // PrintRegisteredScopes logs all the registered scopes and their configured
@@ -200,29 +315,55 @@ func (s *ScopeManager) GetOutputLevel(name string) (Level, error) {
 func (s *ScopeManager) PrintRegisteredScopes() {
 	pad := 7
 
-	names := make([]string, 0, len(s.registry))
-	for n := range s.registry {
-		names = append(names, n)
-		if len(n) > pad {
-			pad = len(n)
+	scopes := s.ListScopes()
+	for _, sc := range scopes {
+		if len(sc.Name) > pad {
+			pad = len(sc.Name)
 		}
 	}
-	sort.Strings(names)
 
 	fmt.Println("registered logging scopes:")
 	fmt.Printf("- %-*s [%-5s]  %s\n",
 		pad,
 		"default",
-		levelToString[Level(atomic.LoadInt32(s.logger.lvl))],
+		levelString(s.logger.Level()),
 		"",
 	)
-	for _, n := range names {
-		sc := s.registry[n]
+	for _, sc := range scopes {
 		fmt.Printf("- %-*s [%-5s]  %s\n",
 			pad,
-			sc.name,
-			levelToString[Level(atomic.LoadInt32(sc.logger.lvl))],
-			sc.description,
+			sc.Name,
+			levelString(sc.Level),
+			sc.Description,
 		)
 	}
 }
+
+// ScopeInfo describes a registered scope.
+type ScopeInfo struct {
+	// Name holds the name of the scope.
+	Name string
+	// Description holds the description provided at registration.
+	Description string
+	// Level holds the current minimum log output level of the scope.
+	Level Level
+}
+
+// ListScopes returns the registered scopes, sorted by name, allowing admin
+// tooling to inspect the logging configuration at runtime. The levels can be
+// changed through SetScopeOutputLevel.
+func (s *ScopeManager) ListScopes() []ScopeInfo {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	scopes := make([]ScopeInfo, 0, len(s.registry))
+	for _, sc := range s.registry {
+		scopes = append(scopes, ScopeInfo{
+			Name:        sc.name,
+			Description: sc.description,
+			Level:       sc.logger.Level(),
+		})
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].Name < scopes[j].Name })
+	return scopes
+}
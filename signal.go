@@ -0,0 +1,70 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// HandleLevelSignals installs signal handlers allowing operators to toggle the
+// log level of l without an admin endpoint: SIGUSR1 raises the level to Debug
+// and SIGUSR2 restores the level active before the first SIGUSR1. Levels at or
+// above Debug are left untouched by SIGUSR1. The returned function removes
+// the signal handlers and may be called more than once.
+func HandleLevelSignals(l *Logger) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		var (
+			prev    Level
+			boosted bool
+		)
+		for {
+			select {
+			case sig := <-ch:
+				switch sig {
+				case syscall.SIGUSR1:
+					if cur := l.Level(); !boosted && cur < Debug {
+						prev, boosted = cur, true
+						l.SetLevel(Debug)
+					}
+				case syscall.SIGUSR2:
+					if boosted {
+						boosted = false
+						l.SetLevel(prev)
+					}
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/tetratelabs/multierror"
+)
+
+// sink is the destination of emitted entries.
+type sink interface {
+	emit(entries ...*Entry) error
+}
+
+// kitSink emits entries to a Go kit logger.
+type kitSink struct {
+	logger log.Logger
+	// keys holds the names of the built-in fields.
+	keys KeyNames
+	// kitLevels enables Go kit level values for the level field.
+	kitLevels bool
+	// timeFormatter optionally renders the timestamp field.
+	timeFormatter TimeFormatter
+	// timeLocation holds the time zone of the timestamp field.
+	timeLocation *time.Location
+}
+
+func newKitSink(logger log.Logger, o *options) kitSink {
+	return kitSink{
+		logger:        logger,
+		keys:          o.keys,
+		kitLevels:     o.kitLevels,
+		timeFormatter: o.timeFormatter,
+		timeLocation:  o.timeLocation,
+	}
+}
+
+func (s kitSink) emit(entries ...*Entry) error {
+	if bl, ok := s.logger.(batchLogger); ok && len(entries) > 1 {
+		records := make([][]interface{}, 0, len(entries))
+		for _, e := range entries {
+			records = append(records, s.keyValues(e))
+		}
+		return bl.logBatch(records)
+	}
+	var mErr error
+	for _, e := range entries {
+		if err := s.logger.Log(s.keyValues(e)...); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr
+}
+
+// keyValues returns the Go kit key-value pairs to log for the provided Entry.
+func (s kitSink) keyValues(e *Entry) []interface{} {
+	args := make([]interface{}, 0, 8+len(e.KeyValues))
+	if s.timeFormatter != nil {
+		args = append(args, s.keys.Time, s.timeFormatter(e.Time.In(s.timeLocation)))
+	}
+	switch {
+	case s.kitLevels && s.keys.Level == defaultKeyNames.Level:
+		args = append(args, s.keys.Message, e.Message, level.Key(), kitLevelValue(e.Level))
+	case s.kitLevels:
+		args = append(args, s.keys.Message, e.Message, s.keys.Level, kitLevelValue(e.Level))
+	default:
+		args = append(args, s.keys.Message, e.Message, s.keys.Level, recordLevelString(e.Level))
+	}
+	if e.Level == Error {
+		args = append(args, s.keys.Error, e.Error)
+	}
+	return append(args, e.KeyValues...)
+}
+
+// encoderSink emits entries to a writer using an Encoder. Each record is
+// encoded into a pooled buffer first, resulting in a single write per record.
+type encoderSink struct {
+	mtx sync.Mutex
+	w   io.Writer
+	enc Encoder
+}
+
+func (s *encoderSink) emit(entries ...*Entry) error {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var mErr error
+	for _, e := range entries {
+		buf.Reset()
+		if err := s.enc.Encode(buf, e); err != nil {
+			mErr = multierror.Append(mErr, err)
+			continue
+		}
+		if _, err := s.w.Write(buf.Bytes()); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr
+}
+
+// WithKitLevels emits the level of records as Go kit level values, see the
+// github.com/go-kit/log/level package, instead of plain strings. This allows
+// Go kit loggers decorated with level.NewFilter, or formatters inspecting
+// level values, to recognize the severity of records. As Go kit has no trace
+// or verbosity levels, these are emitted as debug.
+func WithKitLevels() Option {
+	return func(o *options) {
+		o.kitLevels = true
+	}
+}
+
+// kitLevelValue returns the Go kit level value for lvl.
+func kitLevelValue(lvl Level) level.Value {
+	switch {
+	case lvl <= Error:
+		return level.ErrorValue()
+	case lvl <= Warn:
+		return level.WarnValue()
+	case lvl <= Info:
+		return level.InfoValue()
+	default:
+		return level.DebugValue()
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"io"
+
+	"github.com/tetratelabs/telemetry"
+)
+
+// WithSizeMetric records the size in bytes of each encoded record to the
+// provided Metric, which is expected to be a distribution. This allows the
+// capacity planning of log pipelines to be based on measured record sizes.
+// Sizes are recorded for Loggers constructed from an io.Writer. Loggers
+// wrapping a Go kit logger provided to New don't have access to the encoded
+// output and are unaffected.
+func WithSizeMetric(m telemetry.Metric) Option {
+	return func(o *options) {
+		o.sizeMetric = m
+	}
+}
+
+// measure returns w wrapped to record the size of each write if a size Metric
+// is configured.
+func (o *options) measure(w io.Writer) io.Writer {
+	if o.sizeMetric == nil || w == nil {
+		return w
+	}
+	return &sizeWriter{w: w, m: o.sizeMetric}
+}
+
+// sizeWriter records the size of each successful write to a Metric. It relies
+// on the sinks issuing a single write per record.
+type sizeWriter struct {
+	w io.Writer
+	m telemetry.Metric
+}
+
+// Write implements io.Writer.
+func (s *sizeWriter) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	if n > 0 {
+		s.m.Record(float64(n))
+	}
+	return n, err
+}
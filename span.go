@@ -0,0 +1,55 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "context"
+
+// SpanEventFunc mirrors a log record as an event on the span found in the
+// provided Context. It allows tracing libraries to be bridged without this
+// package depending on them. An OpenTelemetry implementation could look like:
+//
+//	func(ctx context.Context, e *logger.Entry) {
+//		span := trace.SpanFromContext(ctx)
+//		if !span.IsRecording() {
+//			return
+//		}
+//		attrs := make([]attribute.KeyValue, 0, len(e.KeyValues)/2)
+//		for i := 0; i+1 < len(e.KeyValues); i += 2 {
+//			attrs = append(attrs, attribute.String(
+//				fmt.Sprint(e.KeyValues[i]), fmt.Sprint(e.KeyValues[i+1])))
+//		}
+//		span.AddEvent(e.Message, trace.WithAttributes(attrs...))
+//	}
+//
+// Implementations must not retain the Entry.
+type SpanEventFunc func(ctx context.Context, e *Entry)
+
+// WithSpanEvents mirrors emitted Info and Error records as span events using
+// the provided SpanEventFunc, so traces carry the log narrative without
+// double instrumentation.
+func WithSpanEvents(f SpanEventFunc) Option {
+	return func(o *options) {
+		o.spanEvents = f
+	}
+}
+
+// mirrorSpanEvent hands the Entry to the configured SpanEventFunc if it holds
+// an Info or Error record.
+func (l *Logger) mirrorSpanEvent(e *Entry) {
+	if l.opts.spanEvents == nil || e.Level <= None || e.Level > Info {
+		return
+	}
+	l.opts.spanEvents(l.ctx, e)
+}
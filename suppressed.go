@@ -0,0 +1,86 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// suppressionSummary counts Error records suppressed by the level
+// configuration and periodically reports them.
+type suppressionSummary struct {
+	mtx      sync.Mutex
+	interval time.Duration
+	start    time.Time
+	count    int64
+}
+
+// record counts a suppressed record. It returns true for the first record of
+// a window, for which the caller schedules the summary.
+func (s *suppressionSummary) record(now time.Time) (first bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.count++
+	if s.count > 1 {
+		return false
+	}
+	s.start = now
+	return true
+}
+
+// take returns the number of suppressed records in the window ending at now
+// and starts a new window.
+func (s *suppressionSummary) take(now time.Time) (count int64, window time.Duration) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	count, window = s.count, now.Sub(s.start)
+	s.count = 0
+	return count, window
+}
+
+// WithSuppressedErrorSummary enables counting of Error records suppressed by
+// the level configuration, e.g. after SetLevel(None). The first suppressed
+// record opens a window of the provided interval, at the end of which a
+// single summary line reports the number of records suppressed within it,
+// ensuring complete silence never hides an outage. No timers are active while
+// nothing is suppressed. The summary bypasses the level configuration.
+func WithSuppressedErrorSummary(interval time.Duration) Option {
+	return func(o *options) {
+		o.suppressed = &suppressionSummary{interval: interval}
+	}
+}
+
+// reportSuppressed counts a suppressed Error record and schedules the summary
+// line at the end of the window if it opened one.
+func (l *Logger) reportSuppressed() {
+	if !l.opts.suppressed.record(l.opts.clock.Now()) {
+		return
+	}
+	time.AfterFunc(l.opts.suppressed.interval, func() {
+		now := l.opts.clock.Now()
+		count, window := l.opts.suppressed.take(now)
+		_ = l.sink.emit(&Entry{
+			Level: Error,
+			Time:  now,
+			Message: fmt.Sprintf("suppressed %d error records in last %s",
+				count, window.Round(time.Second)),
+			KeyValues: []interface{}{"suppressed", count, "window", window.String()},
+		})
+	})
+}
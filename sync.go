@@ -0,0 +1,60 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/tetratelabs/multierror"
+)
+
+// batchLogger is implemented by Go kit loggers which are able to emit multiple
+// records while holding on to a single writer lock.
+type batchLogger interface {
+	logBatch(records [][]interface{}) error
+}
+
+// syncLogger is the equivalent of Go kit's SyncLogger, which additionally
+// supports emitting a batch of records with a single lock acquisition.
+type syncLogger struct {
+	mtx    sync.Mutex
+	logger log.Logger
+}
+
+func newSyncLogger(logger log.Logger) *syncLogger {
+	return &syncLogger{logger: logger}
+}
+
+// Log implements log.Logger.
+func (s *syncLogger) Log(keyValues ...interface{}) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.logger.Log(keyValues...)
+}
+
+func (s *syncLogger) logBatch(records [][]interface{}) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var mErr error
+	for _, keyValues := range records {
+		if err := s.logger.Log(keyValues...); err != nil {
+			mErr = multierror.Append(mErr, err)
+		}
+	}
+	return mErr
+}
@@ -0,0 +1,160 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SyslogConfig configures the Encoder returned by NewSyslogEncoder.
+type SyslogConfig struct {
+	// Facility holds the syslog facility code, e.g. 1 for user-level or 16
+	// to 23 for local0 to local7. Defaults to 1.
+	Facility int
+	// Hostname holds the HOSTNAME header. If empty, the hostname of the
+	// system is used.
+	Hostname string
+	// AppName holds the APP-NAME header. If empty, the base name of the
+	// executable is used.
+	AppName string
+	// MsgIDKey optionally holds the key of the key-value pair holding the
+	// MSGID header.
+	MsgIDKey string
+	// SDID holds the id of the structured data element holding the key-value
+	// pairs. It must be in the form of name@<private enterprise number> if
+	// not registered with IANA. Defaults to "fields@32473", using the example
+	// enterprise number of RFC 5612.
+	SDID string
+}
+
+// NewSyslogEncoder returns an Encoder emitting records as RFC 5424 syslog
+// messages, so output can be piped into rsyslog or syslog-ng relays
+// unchanged. The level is mapped to the syslog severity of the PRI header and
+// the key-value pairs, including the error of Error records, are emitted as
+// parameters of a single structured data element. Each message is terminated
+// by a newline.
+func NewSyslogEncoder(cfg SyslogConfig) Encoder {
+	if cfg.Facility <= 0 || cfg.Facility > 23 {
+		cfg.Facility = 1
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = filepath.Base(os.Args[0])
+	}
+	if cfg.SDID == "" {
+		cfg.SDID = "fields@32473"
+	}
+	var (
+		hostname = syslogHeader(cfg.Hostname, 255)
+		appName  = syslogHeader(cfg.AppName, 48)
+		procID   = strconv.Itoa(os.Getpid())
+		sdID     = syslogSDName(cfg.SDID)
+	)
+	return EncoderFunc(func(w io.Writer, e *Entry) error {
+		msgID := "-"
+		var sd strings.Builder
+		if e.Level == Error && e.Error != nil {
+			syslogParam(&sd, "error", e.Error.Error())
+		}
+		for i := 0; i < len(e.KeyValues); i += 2 {
+			k := fmt.Sprint(e.KeyValues[i])
+			v := fmt.Sprint(pairValue(e.KeyValues, i))
+			if k == cfg.MsgIDKey && cfg.MsgIDKey != "" {
+				msgID = syslogHeader(v, 32)
+				continue
+			}
+			syslogParam(&sd, k, v)
+		}
+		data := "-"
+		if sd.Len() > 0 {
+			data = "[" + sdID + sd.String() + "]"
+		}
+		_, err := fmt.Fprintf(w, "<%d>1 %s %s %s %s %s %s %s\n",
+			cfg.Facility*8+syslogSeverity(e.Level),
+			e.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+			hostname, appName, procID, msgID, data,
+			strings.NewReplacer("\r", " ", "\n", " ").Replace(e.Message),
+		)
+		return err
+	})
+}
+
+// syslogSeverity returns the syslog severity of lvl.
+func syslogSeverity(lvl Level) int {
+	switch {
+	case lvl == Error:
+		return 3
+	case lvl == Warn:
+		return 4
+	case lvl == Info:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// syslogHeader returns s as header field of at most n printable US-ASCII
+// characters, or the nil value "-" if empty.
+func syslogHeader(s string, n int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > n {
+		s = s[:n]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// syslogSDName returns s as structured data id or parameter name: at most 32
+// printable US-ASCII characters, excluding '=', ' ', ']' and '"'.
+func syslogSDName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > 32 {
+		s = s[:32]
+	}
+	return s
+}
+
+var syslogParamEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
+
+// syslogParam writes a structured data parameter to b.
+func syslogParam(b *strings.Builder, name, value string) {
+	if name = syslogSDName(name); name == "" {
+		return
+	}
+	b.WriteByte(' ')
+	b.WriteString(name)
+	b.WriteString(`="`)
+	b.WriteString(syslogParamEscaper.Replace(value))
+	b.WriteByte('"')
+}
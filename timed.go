@@ -0,0 +1,49 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "github.com/tetratelabs/telemetry"
+
+// WithDurationMetric records the durations measured by Timed, in seconds, to
+// the provided Metric, which is expected to be a distribution.
+func WithDurationMetric(m telemetry.Metric) Option {
+	return func(o *options) {
+		o.durationMetric = m
+	}
+}
+
+// Timed starts measuring the duration of an operation. The returned function
+// stops the measurement and logs an Info record with the provided message and
+// key-value pairs, followed by a duration field holding the elapsed time.
+// Durations are taken from the Clock of the Logger. It is intended to be
+// deferred:
+//
+//	defer l.Timed("request handled", "path", path)()
+func (l *Logger) Timed(msg string, keyValues ...interface{}) func() {
+	start := l.opts.clock.Now()
+	return func() {
+		d := l.opts.clock.Now().Sub(start)
+		if l.opts.durationMetric != nil {
+			l.opts.durationMetric.RecordContext(l.ctx, d.Seconds())
+		}
+		if l.metric != nil {
+			l.metric.RecordContext(l.ctx, 1)
+		}
+		args := make([]interface{}, 0, len(keyValues)+2)
+		args = append(args, keyValues...)
+		args = append(args, "duration", d)
+		l.log(l.infoLevel(), msg, nil, args)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrWriteTimeout is returned by writers created with NewTimeoutWriter if a
+// write did not complete in time, or a previously timed out write is still
+// blocked.
+var ErrWriteTimeout = errors.New("log write timed out")
+
+// deadlineWriter is implemented by writers supporting write deadlines, such as
+// net.Conn and os.File for pipes.
+type deadlineWriter interface {
+	io.Writer
+	SetWriteDeadline(t time.Time) error
+}
+
+// timeoutWriter enforces a timeout on each write.
+type timeoutWriter struct {
+	mtx     sync.Mutex
+	w       io.Writer
+	timeout time.Duration
+	pending chan struct{}
+}
+
+// NewTimeoutWriter returns a writer enforcing the provided timeout on each
+// write to w, so a hung NFS mount or unresponsive network peer can't block the
+// logging path forever. If w supports write deadlines, these are used.
+// Otherwise a watchdog abandons the write once the timeout expires; until that
+// write returns, subsequent writes fail fast with ErrWriteTimeout.
+func NewTimeoutWriter(w io.Writer, timeout time.Duration) io.Writer {
+	return &timeoutWriter{w: w, timeout: timeout}
+}
+
+// Write implements io.Writer.
+func (t *timeoutWriter) Write(p []byte) (int, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if dw, ok := t.w.(deadlineWriter); ok {
+		if err := dw.SetWriteDeadline(time.Now().Add(t.timeout)); err == nil {
+			return dw.Write(p)
+		}
+	}
+
+	if t.pending != nil {
+		select {
+		case <-t.pending:
+			t.pending = nil
+		default:
+			return 0, ErrWriteTimeout
+		}
+	}
+
+	var (
+		n    int
+		err  error
+		done = make(chan struct{})
+		// the abandoned write might still access the buffer after we return
+		buf = append([]byte(nil), p...)
+	)
+	go func() {
+		n, err = t.w.Write(buf)
+		close(done)
+	}()
+
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return n, err
+	case <-timer.C:
+		t.pending = done
+		return 0, ErrWriteTimeout
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import "time"
+
+// TimeFormatter renders the time of a record into the value of the timestamp
+// field.
+type TimeFormatter func(t time.Time) interface{}
+
+// Built-in TimeFormatters.
+var (
+	// RFC3339 renders timestamps with second precision.
+	RFC3339 = TimeLayout(time.RFC3339)
+	// RFC3339Nano renders timestamps with nanosecond precision.
+	RFC3339Nano = TimeLayout(time.RFC3339Nano)
+	// EpochMillis renders timestamps as milliseconds since the Unix epoch.
+	EpochMillis TimeFormatter = func(t time.Time) interface{} {
+		return t.UnixNano() / int64(time.Millisecond)
+	}
+)
+
+// TimeLayout returns a TimeFormatter rendering timestamps using the provided
+// time.Format layout.
+func TimeLayout(layout string) TimeFormatter {
+	return func(t time.Time) interface{} {
+		return t.Format(layout)
+	}
+}
+
+// WithTimestamp prepends a timestamp field, named "ts" unless changed with
+// WithKeyNames, to each record emitted through Go kit, holding the record
+// time rendered by f. If f is nil, RFC3339Nano is used. The record time is
+// taken from the Clock of the Logger, see WithClock, and rendered in UTC
+// unless changed with WithTimeLocation. Custom Encoders receive the time
+// through the Entry instead.
+func WithTimestamp(f TimeFormatter) Option {
+	return func(o *options) {
+		if f == nil {
+			f = RFC3339Nano
+		}
+		o.timeFormatter = f
+	}
+}
+
+// WithTimeLocation sets the time zone in which the timestamp field is
+// rendered, e.g. time.Local or a location obtained through time.LoadLocation.
+// If loc is nil, UTC is used, which is also the default.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(o *options) {
+		if loc == nil {
+			loc = time.UTC
+		}
+		o.timeLocation = loc
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+// Errors reported to the ErrorHandler when constructing a Logger.
+var (
+	ErrNilLogger = errors.New("nil Go kit logger, falling back to stderr")
+	ErrNilWriter = errors.New("nil writer, falling back to stderr")
+)
+
+// ErrNilContext is reported to the ErrorHandler if a nil Context is attached
+// to a Logger and WithNilContextReporting is set.
+var ErrNilContext = errors.New("nil Context attached to Logger, using context.Background")
+
+// WithNilContextReporting treats attaching a nil Context to a Logger through
+// Context or LoggerBuilder.Context as a programming error, reporting
+// ErrNilContext to the ErrorHandler. Regardless of this option, a nil Context
+// is substituted with context.Background.
+func WithNilContextReporting() Option {
+	return func(o *options) {
+		o.reportNilContext = true
+	}
+}
+
+// checkContext returns ctx, or context.Background if ctx is nil.
+func (o *options) checkContext(ctx context.Context) context.Context {
+	if ctx != nil {
+		return ctx
+	}
+	if o.reportNilContext {
+		o.reportError(ErrNilContext)
+	}
+	return context.Background()
+}
+
+// ErrorHandler is called with configuration problems detected while
+// constructing or deriving a Logger, which are recovered from by falling back
+// to sane defaults.
+type ErrorHandler func(err error)
+
+// WithErrorHandler sets the ErrorHandler of the Logger. If not provided, the
+// errors are written to stderr.
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(o *options) {
+		o.errorHandler = h
+	}
+}
+
+// reportError passes err to the configured ErrorHandler.
+func (o *options) reportError(err error) {
+	if o.errorHandler != nil {
+		o.errorHandler(err)
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "telemetry-gokit-log: %v\n", err)
+}
+
+// isNilWriter returns true if w is nil or holds a nil pointer.
+func isNilWriter(w io.Writer) bool {
+	if w == nil {
+		return true
+	}
+	v := reflect.ValueOf(w)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxVerbosity holds the highest verbosity level supported by V. Higher
+// levels are clamped to it.
+const MaxVerbosity = 100
+
+// V returns a Logger emitting its Debug and Info records at verbosity level n,
+// mapped onto fine-grained Debug sub-levels (Debug + n). These records are
+// only emitted if the Logger's level is set to at least Debug + n, allowing
+// teams porting from klog or glog to keep their -v=4 style granularity.
+// Verbosity levels can be configured per scope using the v<n> notation, e.g.
+// "server:v4". Error records are not affected by verbosity.
+func (l *Logger) V(n int) *Logger {
+	switch {
+	case n < 0:
+		n = 0
+	case n > MaxVerbosity:
+		n = MaxVerbosity
+	}
+	newLogger := l.clone(0)
+	newLogger.verbosity = n
+
+	return newLogger
+}
+
+// debugLevel returns the level at which Debug records are emitted.
+func (l *Logger) debugLevel() Level {
+	return Debug + Level(l.verbosity)
+}
+
+// infoLevel returns the level at which Info records are emitted.
+func (l *Logger) infoLevel() Level {
+	if l.verbosity > 0 {
+		return Debug + Level(l.verbosity)
+	}
+	return Info
+}
+
+// levelString returns the textual representation of lvl as used in level
+// configuration. Unnamed verbosity levels above Debug are represented as v<n>.
+func levelString(lvl Level) string {
+	if s, ok := levelToString[lvl]; ok {
+		return s
+	}
+	if lvl > Debug && lvl <= Debug+MaxVerbosity {
+		return "v" + strconv.Itoa(int(lvl-Debug))
+	}
+	return ""
+}
+
+// recordLevelString returns the textual representation of lvl as used in log
+// records. Unnamed levels are logged as debug, or as trace if beyond Trace.
+func recordLevelString(lvl Level) string {
+	if s, ok := levelToString[lvl]; ok {
+		return s
+	}
+	if lvl > Trace {
+		return levelToString[Trace]
+	}
+	if lvl > Debug {
+		return levelToString[Debug]
+	}
+	return ""
+}
+
+// String implements fmt.Stringer. Unnamed verbosity levels above Debug are
+// represented as v<n>.
+func (lvl Level) String() string {
+	if s := levelString(lvl); s != "" {
+		return s
+	}
+	return "level(" + strconv.Itoa(int(lvl)) + ")"
+}
+
+// ParseLevel parses the textual representation of a level as used in
+// configuration files and flags, e.g. "debug", "info" or "error". Debug
+// verbosity levels can be provided using the v<n> notation.
+func ParseLevel(s string) (Level, error) {
+	lvl, ok := parseLevel(s)
+	if !ok {
+		return None, fmt.Errorf("%q is not a valid log level", s)
+	}
+	return lvl, nil
+}
+
+// SetLevelFromString parses the provided level with ParseLevel and sets it as
+// the log level of l.
+func (l *Logger) SetLevelFromString(s string) error {
+	lvl, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	_, err = l.ApplyLevel(lvl)
+	return err
+}
+
+// parseLevel parses the textual representation of a level, including the v<n>
+// notation for verbosity levels.
+func parseLevel(s string) (Level, bool) {
+	s = strings.ToLower(strings.Trim(s, "\r\n\t "))
+	if lvl, ok := stringToLevel[s]; ok {
+		return lvl, true
+	}
+	if !strings.HasPrefix(s, "v") {
+		return None, false
+	}
+	n, err := strconv.Atoi(s[1:])
+	if err != nil || n < 0 || n > MaxVerbosity {
+		return None, false
+	}
+	return Debug + Level(n), true
+}
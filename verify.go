@@ -0,0 +1,106 @@
+// Copyright (c) Tetrate, Inc 2021.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tetratelabs/multierror"
+)
+
+// errSelfTest is the error of the Error record emitted by Verify.
+var errSelfTest = errors.New("logging self-test")
+
+// verifyLevels holds the levels at which Verify emits test records.
+var verifyLevels = []Level{Error, Warn, Info, Debug, Trace}
+
+// VerifyResult holds the outcome of Verify for a single sink.
+type VerifyResult struct {
+	// Sink holds the index of the sink, in order of the sinks provided to
+	// NewMulti. Loggers with a single destination report a single result.
+	Sink int
+	// Err holds the errors encountered while writing the test records.
+	Err error
+}
+
+// Verify emits a test record at every level through the configured pipeline
+// of l and reports the outcome per sink, allowing deploy tooling to validate
+// the logging configuration before rollout. Test records bypass the level
+// configuration and sampling and hold a self_test=true key-value pair.
+// Records of async Loggers are written synchronously. The returned error
+// holds all failures, if any.
+func (l *Logger) Verify() ([]VerifyResult, error) {
+	entries := make([]*Entry, 0, len(verifyLevels))
+	for _, lvl := range verifyLevels {
+		var err error
+		if lvl == Error {
+			err = errSelfTest
+		}
+		entries = append(entries, l.entry(lvl, "logging self-test", err, []interface{}{"self_test", true}))
+	}
+
+	var (
+		mErr    error
+		results []VerifyResult
+	)
+	for i, s := range leafSinks(l.sink) {
+		var sErr error
+		for _, e := range entries {
+			if err := s.emit(e); err != nil {
+				sErr = multierror.Append(sErr, err)
+			}
+		}
+		if sErr != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("sink %d: %w", i, sErr))
+		}
+		results = append(results, VerifyResult{Sink: i, Err: sErr})
+	}
+	return results, mErr
+}
+
+// leafSinks returns the destinations of s, looking through the sinks which
+// wrap or combine other sinks.
+func leafSinks(s sink) []sink {
+	switch t := s.(type) {
+	case *asyncSink:
+		return leafSinks(t.sink)
+	case dryRunSink:
+		return leafSinks(t.sink)
+	case pipelineSink:
+		return leafSinks(t.sink)
+	case levelSink:
+		return leafSinks(t.sink)
+	case *deferredSink:
+		if target := t.sink(); target != nil {
+			return leafSinks(target)
+		}
+		return []sink{s}
+	case teeSink:
+		return leafSinkList(t)
+	case failoverSink:
+		return leafSinkList(t)
+	default:
+		return []sink{s}
+	}
+}
+
+func leafSinkList(list []sink) []sink {
+	var res []sink
+	for _, s := range list {
+		res = append(res, leafSinks(s)...)
+	}
+	return res
+}